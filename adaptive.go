@@ -0,0 +1,85 @@
+package iters
+
+import (
+	"iter"
+	"sync"
+	"time"
+)
+
+// AdaptiveConfig is a configuration of an Adaptive delay.
+type AdaptiveConfig struct {
+	// Min is the minimal and the initial delay. It must be positive for the delay to grow.
+	Min time.Duration
+	// Max is the maximal delay.
+	Max time.Duration
+	// FailureFactor multiplies the delay on a failure. Defaults to 2.
+	FailureFactor float64
+	// SuccessFactor multiplies the delay on a success. Defaults to 1.
+	SuccessFactor float64
+	// SuccessStep is subtracted from the delay on a success, after SuccessFactor is applied. Defaults to Min.
+	// A negative value disables the subtraction, so the delay shrinks only multiplicatively.
+	SuccessStep time.Duration
+}
+
+// Adaptive is a delay adapting to outcomes of operations: failures grow the delay multiplicatively and
+// successes shrink it additively and multiplicatively, within [Min, Max].
+// It is useful for long-running pollers and queue consumers. Adaptive is safe for concurrent use.
+type Adaptive struct {
+	cfg AdaptiveConfig
+
+	mu    sync.Mutex
+	delay time.Duration
+}
+
+// NewAdaptive creates a new Adaptive delay starting with the minimal delay.
+func NewAdaptive(cfg AdaptiveConfig) *Adaptive {
+	if cfg.FailureFactor <= 0 {
+		cfg.FailureFactor = 2
+	}
+	if cfg.SuccessFactor <= 0 {
+		cfg.SuccessFactor = 1
+	}
+	switch {
+	case cfg.SuccessStep == 0:
+		cfg.SuccessStep = cfg.Min
+	case cfg.SuccessStep < 0:
+		cfg.SuccessStep = 0
+	}
+	cfg.Max = max(cfg.Min, cfg.Max)
+	return &Adaptive{cfg: cfg, delay: cfg.Min}
+}
+
+// Delay returns the current delay.
+func (a *Adaptive) Delay() time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.delay
+}
+
+// Success shrinks the delay.
+func (a *Adaptive) Success() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.delay = max(a.cfg.Min, time.Duration(float64(a.delay)*a.cfg.SuccessFactor)-a.cfg.SuccessStep)
+}
+
+// Failure grows the delay.
+func (a *Adaptive) Failure() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.delay = min(a.cfg.Max, time.Duration(float64(a.delay)*a.cfg.FailureFactor))
+}
+
+// Seq returns an infinite sequence of the current delays.
+func (a *Adaptive) Seq() iter.Seq[time.Duration] {
+	return func(yield func(time.Duration) bool) {
+		for {
+			if !yield(a.Delay()) {
+				return
+			}
+		}
+	}
+}
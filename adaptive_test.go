@@ -0,0 +1,71 @@
+package iters
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func ExampleAdaptive() {
+	delay := NewAdaptive(AdaptiveConfig{Min: time.Millisecond, Max: time.Millisecond * 10})
+	for attempt, d := range RetryAfterDelay(context.Background(), Trim(delay.Seq(), 8)) {
+		fmt.Println(attempt, d)
+		if attempt < 5 {
+			delay.Failure()
+		} else {
+			delay.Success()
+		}
+	}
+
+	// Output:
+	// 1 1ms
+	// 2 2ms
+	// 3 4ms
+	// 4 8ms
+	// 5 10ms
+	// 6 9ms
+	// 7 8ms
+	// 8 7ms
+}
+
+func TestAdaptive(t *testing.T) {
+	t.Parallel()
+
+	delay := NewAdaptive(AdaptiveConfig{
+		Min:           time.Second,
+		Max:           time.Minute,
+		FailureFactor: 3,
+		SuccessFactor: 0.5,
+		SuccessStep:   time.Second,
+	})
+	delay.Success()
+	assertEquals(t, time.Second, delay.Delay())
+	delay.Failure()
+	delay.Failure()
+	assertEquals(t, time.Second*9, delay.Delay())
+	delay.Success()
+	assertEquals(t, time.Millisecond*3500, delay.Delay())
+	for range 10 {
+		delay.Failure()
+	}
+	assertEquals(t, time.Minute, delay.Delay())
+
+	for d := range Trim(Jitter(delay.Seq(), 0.1), 10) {
+		if d < time.Second*54 || d > time.Second*66 {
+			t.Fatalf("unexpected jittered delay: %s", d)
+		}
+	}
+}
+
+func TestAdaptive_multiplicative(t *testing.T) {
+	t.Parallel()
+
+	delay := NewAdaptive(AdaptiveConfig{Min: time.Second, Max: time.Minute, SuccessFactor: 0.5, SuccessStep: -1})
+	for range 3 {
+		delay.Failure()
+	}
+	assertEquals(t, time.Second*8, delay.Delay())
+	delay.Success()
+	assertEquals(t, time.Second*4, delay.Delay())
+}
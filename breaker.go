@@ -0,0 +1,256 @@
+package iters
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is the reason of stopping retries when the circuit breaker does not allow an attempt.
+var ErrCircuitOpen = errors.New("iters: circuit breaker is open")
+
+// BreakerState is a state of a CircuitBreaker.
+type BreakerState int
+
+const (
+	// BreakerClosed allows all attempts.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen rejects all attempts until the cool-down period passes.
+	BreakerOpen
+	// BreakerHalfOpen allows a limited number of trial attempts.
+	BreakerHalfOpen
+)
+
+var breakerStates = [...]string{
+	BreakerClosed:   "closed",
+	BreakerOpen:     "open",
+	BreakerHalfOpen: "half-open",
+}
+
+// String returns the name of the state.
+func (s BreakerState) String() string {
+	if s < 0 || int(s) >= len(breakerStates) {
+		return "BreakerState(" + strconv.Itoa(int(s)) + ")"
+	}
+	return breakerStates[s]
+}
+
+// CircuitBreakerConfig is a configuration of a CircuitBreaker.
+type CircuitBreakerConfig struct {
+	// ConsecutiveFailures is the number of failures in a row that opens the breaker. Zero disables the threshold.
+	ConsecutiveFailures int
+	// FailureRate is the rate of failures among the last Window results that opens the breaker.
+	// Zero disables the threshold.
+	FailureRate float64
+	// Window is the number of the last results the failure rate is computed over. Defaults to 100.
+	Window int
+	// MinResults is the minimal number of results in the window to evaluate the failure rate.
+	// Defaults to Window.
+	MinResults int
+	// CoolDown is the time the breaker stays open before it becomes half-open.
+	CoolDown time.Duration
+	// HalfOpenAttempts is the number of trial attempts allowed in the half-open state.
+	// The breaker closes when all of them succeed. Defaults to 1.
+	HalfOpenAttempts int
+	// OnStateChange is called on every state transition. It may be called concurrently.
+	OnStateChange func(from, to BreakerState)
+	// Clock is the time source. Defaults to the system clock.
+	Clock Clock
+}
+
+// CircuitBreaker stops attempts to call a failing dependency.
+// The breaker opens when the failure thresholds are reached and rejects attempts during the cool-down period.
+// Then it becomes half-open and allows trial attempts. It closes if they succeed or opens again otherwise.
+// CircuitBreaker is safe for concurrent use.
+type CircuitBreaker struct {
+	cfg CircuitBreakerConfig
+
+	mu          sync.Mutex
+	state       BreakerState
+	openedAt    time.Time
+	consecutive int
+	results     []bool // ring buffer of the last results, true is a failure
+	next        int
+	count       int
+	failures    int
+	trials      int
+	successes   int
+}
+
+// NewCircuitBreaker creates a new closed CircuitBreaker.
+func NewCircuitBreaker(cfg CircuitBreakerConfig) *CircuitBreaker {
+	if cfg.Window <= 0 {
+		cfg.Window = 100
+	}
+	if cfg.MinResults <= 0 || cfg.MinResults > cfg.Window {
+		cfg.MinResults = cfg.Window
+	}
+	if cfg.HalfOpenAttempts <= 0 {
+		cfg.HalfOpenAttempts = 1
+	}
+	cfg.Clock = clockOrSystem(cfg.Clock)
+
+	b := &CircuitBreaker{cfg: cfg}
+	if cfg.FailureRate > 0 {
+		b.results = make([]bool, cfg.Window)
+	}
+	return b
+}
+
+// State returns the current state of the breaker.
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen && b.cooledDown() {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
+
+// Allow reports whether an attempt is allowed. Each allowed attempt must be followed by Success, Failure or Release.
+func (b *CircuitBreaker) Allow() bool {
+	b.mu.Lock()
+	from := b.state
+	allowed := b.allow()
+	to := b.state
+	b.mu.Unlock()
+
+	b.notify(from, to)
+	return allowed
+}
+
+// Success records a successful attempt.
+func (b *CircuitBreaker) Success() {
+	b.mu.Lock()
+	from := b.state
+	switch b.state {
+	case BreakerClosed:
+		b.consecutive = 0
+		b.record(false)
+	case BreakerHalfOpen:
+		b.successes++
+		if b.successes >= b.cfg.HalfOpenAttempts {
+			b.setState(BreakerClosed)
+		}
+	case BreakerOpen:
+	}
+	to := b.state
+	b.mu.Unlock()
+
+	b.notify(from, to)
+}
+
+// Failure records a failed attempt.
+func (b *CircuitBreaker) Failure() {
+	b.mu.Lock()
+	from := b.state
+	switch b.state {
+	case BreakerClosed:
+		b.consecutive++
+		b.record(true)
+		if b.tripped() {
+			b.setState(BreakerOpen)
+		}
+	case BreakerHalfOpen:
+		b.setState(BreakerOpen)
+	case BreakerOpen:
+	}
+	to := b.state
+	b.mu.Unlock()
+
+	b.notify(from, to)
+}
+
+// Release releases an allowed attempt without recording its result, for example, if the caller cancelled it
+// or the error is not a failure of the dependency. In the half-open state it allows another trial attempt.
+func (b *CircuitBreaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerHalfOpen && b.trials > 0 {
+		b.trials--
+	}
+}
+
+func (b *CircuitBreaker) allow() bool {
+	switch b.state {
+	case BreakerOpen:
+		if !b.cooledDown() {
+			return false
+		}
+		b.setState(BreakerHalfOpen)
+		fallthrough
+	case BreakerHalfOpen:
+		if b.trials >= b.cfg.HalfOpenAttempts {
+			return false
+		}
+		b.trials++
+		return true
+	default:
+		return true
+	}
+}
+
+func (b *CircuitBreaker) cooledDown() bool {
+	return b.cfg.Clock.Now().Sub(b.openedAt) >= b.cfg.CoolDown
+}
+
+// record adds the result to the failure rate window.
+func (b *CircuitBreaker) record(failure bool) {
+	if b.results == nil {
+		return
+	}
+	if b.count == len(b.results) {
+		if b.results[b.next] {
+			b.failures--
+		}
+	} else {
+		b.count++
+	}
+	b.results[b.next] = failure
+	if failure {
+		b.failures++
+	}
+	b.next = (b.next + 1) % len(b.results)
+}
+
+// tripped reports whether the failure thresholds are reached.
+func (b *CircuitBreaker) tripped() bool {
+	if b.cfg.ConsecutiveFailures > 0 && b.consecutive >= b.cfg.ConsecutiveFailures {
+		return true
+	}
+	return b.cfg.FailureRate > 0 && b.count >= b.cfg.MinResults &&
+		float64(b.failures)/float64(b.count) >= b.cfg.FailureRate
+}
+
+// setState changes the state and resets the counters of the new state.
+func (b *CircuitBreaker) setState(state BreakerState) {
+	b.state = state
+	b.trials, b.successes = 0, 0
+	switch state {
+	case BreakerOpen:
+		b.openedAt = b.cfg.Clock.Now()
+	case BreakerClosed:
+		b.consecutive, b.next, b.count, b.failures = 0, 0, 0, 0
+	case BreakerHalfOpen:
+	}
+}
+
+// notify calls the state change callback. Must be called without the lock held.
+func (b *CircuitBreaker) notify(from, to BreakerState) {
+	if from != to && b.cfg.OnStateChange != nil {
+		b.cfg.OnStateChange(from, to)
+	}
+}
+
+// WithCircuitBreaker sets the circuit breaker that gates attempts.
+// Retries stop with StopCircuitOpen when the breaker does not allow an attempt.
+// Do and DoValue record results of attempts in the breaker, in other cases the caller must
+// call Success, Failure or Release after each attempt.
+func WithCircuitBreaker(b *CircuitBreaker) RetryOption {
+	return func(cfg *retryConfig) {
+		cfg.breaker = b
+	}
+}
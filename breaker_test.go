@@ -0,0 +1,204 @@
+package iters_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/gotidy/iters"
+	"github.com/gotidy/iters/iterstest"
+)
+
+func ExampleCircuitBreaker() {
+	breaker := iters.NewCircuitBreaker(iters.CircuitBreakerConfig{
+		ConsecutiveFailures: 3,
+		CoolDown:            time.Minute,
+		OnStateChange: func(from, to iters.BreakerState) {
+			fmt.Println(from, "->", to)
+		},
+	})
+
+	err := iters.Do(context.Background(), iters.Repeat(time.Millisecond), func(context.Context) error {
+		return io.EOF
+	}, iters.WithCircuitBreaker(breaker))
+	fmt.Println(errors.Is(err, iters.ErrCircuitOpen))
+
+	// Output:
+	// closed -> open
+	// true
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	clock := iterstest.NewClock(time.Now())
+	var transitions []string
+	breaker := iters.NewCircuitBreaker(iters.CircuitBreakerConfig{
+		ConsecutiveFailures: 2,
+		CoolDown:            time.Second,
+		HalfOpenAttempts:    2,
+		Clock:               clock,
+		OnStateChange: func(from, to iters.BreakerState) {
+			transitions = append(transitions, from.String()+"->"+to.String())
+		},
+	})
+
+	expectState := func(state iters.BreakerState) {
+		t.Helper()
+		if actual := breaker.State(); actual != state {
+			t.Fatalf("expected state %s, actual: %s", state, actual)
+		}
+	}
+
+	breaker.Failure()
+	breaker.Success()
+	breaker.Failure()
+	expectState(iters.BreakerClosed)
+	breaker.Failure()
+	expectState(iters.BreakerOpen)
+	if breaker.Allow() {
+		t.Fatal("open breaker allowed an attempt")
+	}
+
+	clock.Advance(time.Second)
+	expectState(iters.BreakerHalfOpen)
+	if !breaker.Allow() || !breaker.Allow() {
+		t.Fatal("half-open breaker rejected a trial attempt")
+	}
+	if breaker.Allow() {
+		t.Fatal("half-open breaker allowed too many trial attempts")
+	}
+	breaker.Failure()
+	expectState(iters.BreakerOpen)
+
+	clock.Advance(time.Second)
+	if !breaker.Allow() || !breaker.Allow() {
+		t.Fatal("half-open breaker rejected a trial attempt")
+	}
+	breaker.Success()
+	expectState(iters.BreakerHalfOpen)
+	breaker.Success()
+	expectState(iters.BreakerClosed)
+
+	expected := []string{
+		"closed->open", "open->half-open", "half-open->open", "open->half-open", "half-open->closed",
+	}
+	if fmt.Sprint(transitions) != fmt.Sprint(expected) {
+		t.Errorf("expected transitions %v, actual: %v", expected, transitions)
+	}
+}
+
+func TestCircuitBreaker_failureRate(t *testing.T) {
+	t.Parallel()
+
+	breaker := iters.NewCircuitBreaker(iters.CircuitBreakerConfig{
+		FailureRate: 0.5,
+		Window:      10,
+		MinResults:  4,
+		CoolDown:    time.Hour,
+	})
+	breaker.Failure()
+	breaker.Failure()
+	breaker.Failure()
+	if breaker.State() != iters.BreakerClosed {
+		t.Fatal("breaker opened before the minimal number of results")
+	}
+	for range 7 {
+		breaker.Success()
+	}
+	// The window is full, the first failures are evicted: 0 failures of 10 results.
+	for range 4 {
+		breaker.Success()
+	}
+	// 4 failures of 10 results.
+	for range 4 {
+		breaker.Failure()
+	}
+	if breaker.State() != iters.BreakerClosed {
+		t.Fatal("breaker opened below the failure rate")
+	}
+	breaker.Failure()
+	if breaker.State() != iters.BreakerOpen {
+		t.Fatal("breaker is not opened at the failure rate")
+	}
+}
+
+func TestCircuitBreaker_retry(t *testing.T) {
+	t.Parallel()
+
+	breaker := iters.NewCircuitBreaker(iters.CircuitBreakerConfig{ConsecutiveFailures: 1, CoolDown: time.Hour})
+	breaker.Failure()
+
+	var outcome iters.RetryOutcome
+	for range iters.Retry(context.Background(), iters.Repeat(time.Duration(0)),
+		iters.WithCircuitBreaker(breaker), iters.WithOutcome(&outcome)) {
+		t.Fatal("attempt is not short-circuited")
+	}
+	if outcome.Reason != iters.StopCircuitOpen || outcome.Attempts != 0 {
+		t.Errorf("unexpected outcome: %+v", outcome)
+	}
+}
+
+func TestCircuitBreaker_openedDuringWait(t *testing.T) {
+	t.Parallel()
+
+	clock := iterstest.NewClock(time.Now())
+	breaker := iters.NewCircuitBreaker(iters.CircuitBreakerConfig{ConsecutiveFailures: 1, CoolDown: time.Hour, Clock: clock})
+	budget := iters.NewRetryBudget(iters.RetryBudgetConfig{MinPerSecond: 1, Clock: clock})
+
+	var outcome iters.RetryOutcome
+	done := make(chan int)
+	go func() {
+		done <- iters.Count2(iters.RetryAfterDelay(context.Background(), iters.Repeat(time.Second), iters.WithClock(clock),
+			iters.WithCircuitBreaker(breaker), iters.WithRetryBudget(budget), iters.WithOutcome(&outcome)))
+	}()
+	clock.BlockUntil(1)
+	// Another caller opens the breaker while the retry waits.
+	if !breaker.Allow() {
+		t.Fatal("the closed breaker does not allow an attempt")
+	}
+	breaker.Failure()
+	clock.Advance(time.Second)
+	if n := <-done; n != 0 || outcome.Reason != iters.StopCircuitOpen {
+		t.Errorf("unexpected outcome: %d attempts, %+v", n, outcome)
+	}
+	if !budget.Withdraw() {
+		t.Error("the budget is spent by the short-circuited retry")
+	}
+}
+
+func TestCircuitBreaker_callerErrors(t *testing.T) {
+	t.Parallel()
+
+	clock := iterstest.NewClock(time.Now())
+	breaker := iters.NewCircuitBreaker(iters.CircuitBreakerConfig{ConsecutiveFailures: 1, CoolDown: time.Minute, Clock: clock})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_ = iters.Do(ctx, iters.Repeat(time.Duration(0)), func(ctx context.Context) error {
+		return ctx.Err()
+	}, iters.WithCircuitBreaker(breaker))
+	_ = iters.Do(context.Background(), iters.Repeat(time.Duration(0)), func(context.Context) error {
+		return iters.Permanent(errors.New("invalid request"))
+	}, iters.WithCircuitBreaker(breaker))
+	if breaker.State() != iters.BreakerClosed {
+		t.Fatalf("caller errors opened the breaker: %s", breaker.State())
+	}
+
+	// A released trial attempt allows another one.
+	breaker.Failure()
+	clock.Advance(time.Minute)
+	if !breaker.Allow() || breaker.Allow() {
+		t.Fatal("expected a single trial attempt")
+	}
+	breaker.Release()
+	if !breaker.Allow() {
+		t.Fatal("the released trial attempt is not allowed again")
+	}
+	breaker.Success()
+	if breaker.State() != iters.BreakerClosed {
+		t.Errorf("unexpected state: %s", breaker.State())
+	}
+}
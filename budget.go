@@ -0,0 +1,98 @@
+package iters
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrRetryBudgetExhausted is the reason of stopping retries when the retry budget is exhausted.
+var ErrRetryBudgetExhausted = errors.New("iters: retry budget exhausted")
+
+// RetryBudgetConfig is a configuration of a RetryBudget.
+type RetryBudgetConfig struct {
+	// Ratio is the number of retries earned by a successful request. For example, 0.1 allows one retry per
+	// ten successful requests.
+	Ratio float64
+	// MaxRetries is the maximal number of retries that can be earned by successful requests. Defaults to 10.
+	MaxRetries float64
+	// MinPerSecond is the number of retries per second allowed regardless of successful requests.
+	MinPerSecond int
+	// Clock is the time source. Defaults to the system clock.
+	Clock Clock
+}
+
+// RetryBudget limits retries shared by many retry loops, as a ratio of successful requests
+// with a minimal number of retries per second. RetryBudget is safe for concurrent use.
+type RetryBudget struct {
+	cfg RetryBudgetConfig
+
+	mu       sync.Mutex
+	earned   float64
+	floor    float64
+	refilled time.Time
+}
+
+// NewRetryBudget creates a new RetryBudget. The budget starts with the minimal number of retries per second.
+func NewRetryBudget(cfg RetryBudgetConfig) *RetryBudget {
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = 10
+	}
+	cfg.Clock = clockOrSystem(cfg.Clock)
+	return &RetryBudget{
+		cfg:      cfg,
+		floor:    float64(cfg.MinPerSecond),
+		refilled: cfg.Clock.Now(),
+	}
+}
+
+// Success records a successful request and earns retries.
+func (b *RetryBudget) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.earned = min(b.cfg.MaxRetries, b.earned+b.cfg.Ratio)
+}
+
+// Withdraw spends a retry. Returns false if the budget is exhausted.
+func (b *RetryBudget) Withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.cfg.Clock.Now()
+	elapsed := now.Sub(b.refilled)
+	b.refilled = now
+	b.floor = min(float64(b.cfg.MinPerSecond), b.floor+elapsed.Seconds()*float64(b.cfg.MinPerSecond))
+
+	switch {
+	case b.floor >= 1:
+		b.floor--
+	case b.earned >= 1:
+		b.earned--
+	default:
+		return false
+	}
+	return true
+}
+
+// Refund returns a withdrawn retry that was not made, for example, because the caller cancelled it.
+func (b *RetryBudget) Refund() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.floor+1 <= float64(b.cfg.MinPerSecond) {
+		b.floor++
+		return
+	}
+	b.earned = min(b.cfg.MaxRetries, b.earned+1)
+}
+
+// WithRetryBudget sets the retry budget consulted before each retry.
+// Retries stop with StopBudgetExhausted when the budget is exhausted.
+// Do and DoValue record successful requests in the budget, in other cases the caller must
+// call Success after each successful request.
+func WithRetryBudget(b *RetryBudget) RetryOption {
+	return func(cfg *retryConfig) {
+		cfg.budget = b
+	}
+}
@@ -0,0 +1,145 @@
+package iters_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/gotidy/iters"
+	"github.com/gotidy/iters/iterstest"
+)
+
+func TestRetryBudget(t *testing.T) {
+	t.Parallel()
+
+	clock := iterstest.NewClock(time.Now())
+	budget := iters.NewRetryBudget(iters.RetryBudgetConfig{
+		Ratio:        0.5,
+		MaxRetries:   2,
+		MinPerSecond: 2,
+		Clock:        clock,
+	})
+
+	withdraw := func(expected int) {
+		t.Helper()
+		n := 0
+		for budget.Withdraw() {
+			n++
+		}
+		if n != expected {
+			t.Fatalf("expected %d retries, actual: %d", expected, n)
+		}
+	}
+
+	withdraw(2)
+	clock.Advance(time.Millisecond * 500)
+	withdraw(1)
+	for range 10 {
+		budget.Success()
+	}
+	withdraw(2)
+	clock.Advance(time.Minute)
+	budget.Success()
+	budget.Success()
+	withdraw(3)
+}
+
+func TestRetryBudget_retry(t *testing.T) {
+	t.Parallel()
+
+	clock := iterstest.NewClock(time.Now())
+	budget := iters.NewRetryBudget(iters.RetryBudgetConfig{Ratio: 1, MinPerSecond: 1, Clock: clock})
+
+	var outcome iters.RetryOutcome
+	err := iters.Do(context.Background(), iters.Repeat(time.Duration(0)), func(context.Context) error {
+		return io.EOF
+	}, iters.WithRetryBudget(budget), iters.WithOutcome(&outcome))
+	if !errors.Is(err, iters.ErrRetryBudgetExhausted) {
+		t.Errorf("expected ErrRetryBudgetExhausted, actual: %v", err)
+	}
+	if outcome.Reason != iters.StopBudgetExhausted || outcome.Attempts != 2 {
+		t.Errorf("unexpected outcome: %+v", outcome)
+	}
+
+	calls := 0
+	err = iters.Do(context.Background(), iters.Repeat(time.Duration(0)), func(context.Context) error {
+		calls++
+		if calls == 1 {
+			return io.EOF
+		}
+		return nil
+	}, iters.WithRetryBudget(budget))
+	if err == nil {
+		t.Fatal("retry is allowed by the exhausted budget")
+	}
+	if err := iters.Do(context.Background(), nil, func(context.Context) error { return nil },
+		iters.WithRetryBudget(budget)); err != nil {
+		t.Fatal(err)
+	}
+	if !budget.Withdraw() {
+		t.Error("successful request did not earn a retry")
+	}
+}
+
+func TestRetryBudget_noWait(t *testing.T) {
+	t.Parallel()
+
+	clock := iterstest.NewClock(time.Now())
+	budget := iters.NewRetryBudget(iters.RetryBudgetConfig{Ratio: 1, MinPerSecond: 1, Clock: clock})
+	spent := 0
+	for budget.Withdraw() {
+		spent++
+	}
+	if spent != 1 {
+		t.Fatalf("unexpected budget: %d", spent)
+	}
+
+	// The exhausted budget stops retries without waiting for the delay, the fake clock is never advanced.
+	var outcome iters.RetryOutcome
+	n := iters.Count2(iters.Retry(context.Background(), iters.Repeat(time.Hour), iters.WithClock(clock),
+		iters.WithRetryBudget(budget), iters.WithOutcome(&outcome)))
+	if n != 1 || outcome.Reason != iters.StopBudgetExhausted || outcome.Waited != 0 {
+		t.Errorf("unexpected outcome: %d attempts, %+v", n, outcome)
+	}
+
+	// A retry rejected by the circuit breaker does not spend the budget.
+	clock.Advance(time.Second)
+	breaker := iters.NewCircuitBreaker(iters.CircuitBreakerConfig{ConsecutiveFailures: 1, CoolDown: time.Hour, Clock: clock})
+	breaker.Failure()
+	n = iters.Count2(iters.RetryAfterDelay(context.Background(), iters.Repeat(time.Duration(0)), iters.WithClock(clock),
+		iters.WithRetryBudget(budget), iters.WithCircuitBreaker(breaker), iters.WithOutcome(&outcome)))
+	if n != 0 || outcome.Reason != iters.StopCircuitOpen {
+		t.Errorf("unexpected outcome: %d attempts, %+v", n, outcome)
+	}
+	if !budget.Withdraw() {
+		t.Error("the budget is spent by the rejected retry")
+	}
+}
+
+func TestRetryBudget_refund(t *testing.T) {
+	t.Parallel()
+
+	clock := iterstest.NewClock(time.Now())
+	budget := iters.NewRetryBudget(iters.RetryBudgetConfig{MinPerSecond: 1, Clock: clock})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var outcome iters.RetryOutcome
+	done := make(chan int)
+	go func() {
+		done <- iters.Count2(iters.RetryAfterDelay(ctx, iters.Repeat(time.Hour), iters.WithClock(clock),
+			iters.WithRetryBudget(budget), iters.WithOutcome(&outcome)))
+	}()
+	clock.BlockUntil(1)
+	cancel()
+	if n := <-done; n != 0 || outcome.Reason != iters.StopCanceled {
+		t.Errorf("unexpected outcome: %d attempts, %+v", n, outcome)
+	}
+	if !budget.Withdraw() {
+		t.Error("the retry cancelled during the wait is not refunded")
+	}
+	if budget.Withdraw() {
+		t.Error("the refund exceeds the withdrawn retry")
+	}
+}
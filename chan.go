@@ -0,0 +1,89 @@
+package iters
+
+import (
+	"context"
+	"iter"
+)
+
+// FromChan returns sequence of values received from the channel until it is closed.
+func FromChan[V any](ch <-chan V) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for v := range ch {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// FromChanCtx returns sequence of values received from the channel until it is closed or the context is done.
+func FromChanCtx[V any](ctx context.Context, ch <-chan V) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case v, ok := <-ch:
+				if !ok || !yield(v) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// ToChan runs the sequence in a goroutine and sends its values to the returned channel with the buffer size.
+// The channel is closed when the sequence ends or the context is done. The goroutine returns when
+// the channel is closed, so the consumer must either receive all values or cancel the context.
+// If the context is done, the goroutine stops at the next value of the sequence.
+func ToChan[V any](ctx context.Context, seq iter.Seq[V], buffer int) <-chan V {
+	ch := make(chan V, max(0, buffer))
+	go func() {
+		defer close(ch)
+		for v := range seq {
+			select {
+			case ch <- v:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// Prefetch runs the sequence in a goroutine and keeps up to n values buffered ahead of the consumer,
+// so the producer and the consumer work concurrently. The iteration stops when the sequence ends or the context is done.
+// When the consumer stops the iteration or the context is done, the producer is stopped at its next value
+// and the iteration returns without waiting for it, as in FanIn. If the producer panics, the panic is propagated
+// to the consumer.
+func Prefetch[V any](ctx context.Context, seq iter.Seq[V], n int) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		done := make(chan struct{})
+		defer close(done)
+
+		values := make(chan V, max(0, n))
+		exits := make(chan producerExit)
+		produce(seq, func(v V) bool {
+			select {
+			case values <- v:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}, func() { close(values) }, exits, done)
+
+		for v := range FromChanCtx(ctx, values) {
+			if !yield(v) {
+				return
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if exit := <-exits; exit.panicked {
+			panic(exit.value)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package iters
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func ExampleFromChan() {
+	ch := make(chan int, 3)
+	ch <- 1
+	ch <- 2
+	ch <- 3
+	close(ch)
+	fmt.Println(slices.Collect(FromChan(ch)))
+
+	// Output:
+	// [1 2 3]
+}
+
+func ExampleToChan() {
+	for v := range ToChan(context.Background(), Of(1, 2, 3), 1) {
+		fmt.Println(v)
+	}
+
+	// Output:
+	// 1
+	// 2
+	// 3
+}
+
+func TestFromChanCtx(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := make(chan int)
+	go func() {
+		ch <- 1
+		cancel()
+	}()
+	assertEquals(t, fmt.Sprint([]int{1}), fmt.Sprint(slices.Collect(FromChanCtx(ctx, ch))))
+}
+
+func TestToChan(t *testing.T) {
+	// The test is not parallel, because it counts goroutines.
+	before := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	chans := []<-chan int{
+		ToChan(ctx, Repeat(1), 0),
+		ToChan(ctx, Repeat(1), 10),
+	}
+	for _, ch := range chans {
+		<-ch
+	}
+	chans = append(chans, ToChan(ctx, FromChanCtx(ctx, make(chan int)), 0))
+	cancel()
+	for _, ch := range chans {
+		Count(FromChan(ch))
+	}
+	for _, ch := range []<-chan int{ToChan(context.Background(), Of(1, 2, 3), 0), ToChan(context.Background(), Of[int](), 5)} {
+		Count(FromChan(ch))
+	}
+
+	assertNoLeaks(t, before)
+}
+
+// assertNoLeaks waits until the number of goroutines drops to the specified number.
+func assertNoLeaks(t *testing.T, goroutines int) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > goroutines {
+		if time.Now().After(deadline) {
+			t.Fatalf("goroutines leaked: %d, expected: %d", runtime.NumGoroutine(), goroutines)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func ExamplePrefetch() {
+	pages := func(yield func(int) bool) {
+		for page := range 3 {
+			time.Sleep(time.Millisecond * 10) // Fetching the page.
+			if !yield(page) {
+				return
+			}
+		}
+	}
+	for page := range Prefetch(context.Background(), pages, 2) {
+		time.Sleep(time.Millisecond * 10) // Writing the page, while the next one is fetched.
+		fmt.Println(page)
+	}
+
+	// Output:
+	// 0
+	// 1
+	// 2
+}
+
+func TestPrefetch(t *testing.T) {
+	t.Run("read ahead", func(t *testing.T) {
+		var produced atomic.Int64
+		seq := func(yield func(int) bool) {
+			for i := range 10 {
+				produced.Add(1)
+				if !yield(i) {
+					return
+				}
+			}
+		}
+		var consumed []int
+		for v := range Prefetch(context.Background(), seq, 3) {
+			if v == 0 {
+				// The first value, 3 buffered values and 1 value blocked on sending.
+				deadline := time.Now().Add(time.Second)
+				for produced.Load() < 5 && time.Now().Before(deadline) {
+					time.Sleep(time.Millisecond)
+				}
+				assertEquals(t, int64(5), produced.Load())
+			}
+			consumed = append(consumed, v)
+		}
+		assertEquals(t, fmt.Sprint([]int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}), fmt.Sprint(consumed))
+	})
+
+	t.Run("break", func(t *testing.T) {
+		stopped := make(chan struct{})
+		assertEquals(t, 3, Count(Trim(Prefetch(context.Background(), infinite(stopped), 2), 3)))
+		<-stopped
+	})
+
+	t.Run("idle", func(t *testing.T) {
+		ch := make(chan int)
+		stopped := make(chan struct{})
+		seq := func(yield func(int) bool) {
+			defer close(stopped)
+			if !yield(1) {
+				return
+			}
+			for v := range FromChan(ch) {
+				if !yield(v) {
+					return
+				}
+			}
+		}
+		// The iteration returns while the producer is blocked, it returns when the sequence ends.
+		for v := range Prefetch(context.Background(), seq, 2) {
+			assertEquals(t, 1, v)
+			break
+		}
+		close(ch)
+		<-stopped
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		stopped := make(chan struct{})
+		count := 0
+		for range Prefetch(ctx, infinite(stopped), 2) {
+			count++
+			if count == 3 {
+				cancel()
+			}
+		}
+		assertEquals(t, true, count >= 3)
+		<-stopped
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		seq := func(yield func(int) bool) {
+			yield(1)
+			panic("producer")
+		}
+		defer func() {
+			assertEquals(t, any("producer"), recover())
+		}()
+		Count(Prefetch(context.Background(), seq, 1))
+		t.Fatal("the panic is not propagated")
+	})
+}
@@ -0,0 +1,97 @@
+package iters
+
+import (
+	"errors"
+	"time"
+)
+
+// PermanentError marks an error as permanent. Do and DoValue stop retrying when an operation returns it.
+type PermanentError struct {
+	Err error
+}
+
+// Permanent wraps the error into PermanentError. Returns nil if err is nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{Err: err}
+}
+
+// Error implements the error interface.
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// IsPermanent reports whether the error or any error in its chain is a PermanentError.
+func IsPermanent(err error) bool {
+	var permanent *PermanentError
+	return errors.As(err, &permanent)
+}
+
+// RetryAfterError is an error with the delay requested before the next attempt,
+// for example, by the Retry-After header of an HTTP response.
+type RetryAfterError struct {
+	Err   error
+	After time.Duration
+}
+
+// RetryAfter wraps the error into RetryAfterError with the delay requested before the next attempt.
+// Returns nil if err is nil.
+func RetryAfter(err error, after time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &RetryAfterError{Err: err, After: after}
+}
+
+// Error implements the error interface.
+func (e *RetryAfterError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// RetryDecision is a decision of a Classifier about an attempt error.
+type RetryDecision struct {
+	// Stop reports that the error is terminal and no more attempts should be made.
+	Stop bool
+	// After, if positive, replaces the next delay of the delays sequence.
+	After time.Duration
+}
+
+var (
+	// RetryDecisionRetry retries after the next delay of the delays sequence.
+	RetryDecisionRetry = RetryDecision{}
+	// RetryDecisionStop stops retrying.
+	RetryDecisionStop = RetryDecision{Stop: true}
+)
+
+// RetryDecisionAfter retries after the specified delay instead of the next delay of the delays sequence.
+func RetryDecisionAfter(d time.Duration) RetryDecision {
+	return RetryDecision{After: d}
+}
+
+// Classifier decides whether an attempt error should be retried.
+type Classifier func(err error) RetryDecision
+
+// DefaultClassifier stops on permanent errors, retries after the requested delay on RetryAfterError
+// and retries all others.
+func DefaultClassifier(err error) RetryDecision {
+	if IsPermanent(err) {
+		return RetryDecisionStop
+	}
+	var retryAfter *RetryAfterError
+	if errors.As(err, &retryAfter) {
+		return RetryDecisionAfter(retryAfter.After)
+	}
+	return RetryDecisionRetry
+}
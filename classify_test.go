@@ -0,0 +1,88 @@
+package iters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+)
+
+func ExamplePermanent() {
+	calls := 0
+	err := Do(context.Background(), Repeat(time.Millisecond), func(context.Context) error {
+		calls++
+		if calls == 3 {
+			return Permanent(io.ErrUnexpectedEOF)
+		}
+		return io.EOF
+	})
+	fmt.Println(calls, IsPermanent(err))
+
+	// Output:
+	// 3 true
+}
+
+func ExampleWithClassifier() {
+	errThrottled := errors.New("throttled")
+	err := Do(context.Background(), Trim(Repeat(time.Hour), 3), func(context.Context) error {
+		return errThrottled
+	}, WithClassifier(func(err error) RetryDecision {
+		if errors.Is(err, errThrottled) {
+			return RetryDecisionAfter(time.Millisecond)
+		}
+		return RetryDecisionStop
+	}))
+	fmt.Println(err)
+
+	// Output:
+	// attempt 0: throttled
+	// attempt 1 after 1ms: throttled
+	// attempt 2 after 1ms: throttled
+	// attempt 3 after 1ms: throttled
+}
+
+func TestClassifier(t *testing.T) {
+	t.Parallel()
+
+	t.Run("stop", func(t *testing.T) {
+		calls := 0
+		_ = Do(context.Background(), Repeat(time.Duration(0)), func(context.Context) error {
+			calls++
+			return io.EOF
+		}, WithClassifier(func(error) RetryDecision { return RetryDecisionStop }))
+		assertEquals(t, 1, calls)
+	})
+
+	t.Run("permanent overrides classifier", func(t *testing.T) {
+		calls := 0
+		err := Do(context.Background(), Repeat(time.Duration(0)), func(context.Context) error {
+			calls++
+			return Permanent(io.EOF)
+		}, WithClassifier(func(error) RetryDecision { return RetryDecisionRetry }))
+		assertEquals(t, 1, calls)
+		if !errors.Is(err, io.EOF) {
+			t.Errorf("expected io.EOF, actual: %v", err)
+		}
+	})
+
+	t.Run("cancelled context is terminal", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		calls := 0
+		err := Do(ctx, Repeat(time.Duration(0)), func(context.Context) error {
+			calls++
+			cancel()
+			return io.EOF
+		}, WithClassifier(func(error) RetryDecision { return RetryDecisionRetry }))
+		assertEquals(t, 1, calls)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, actual: %v", err)
+		}
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		assertEquals(t, nil, Permanent(nil))
+		assertEquals(t, false, IsPermanent(io.EOF))
+	})
+}
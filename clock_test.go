@@ -0,0 +1,138 @@
+package iters_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gotidy/iters"
+	"github.com/gotidy/iters/iterstest"
+)
+
+func ExampleRetry_ctx() {
+	clock := iterstest.NewClock(time.Now())
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		for attempt, delay := range iters.Retry(ctx, iters.Repeat(time.Millisecond*100), iters.WithClock(clock)) {
+			fmt.Println(attempt, delay)
+		}
+		fmt.Println("stopped")
+		close(done)
+	}()
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Millisecond * 100)
+	clock.BlockUntil(1)
+	cancel()
+	<-done
+
+	// Output:
+	// 0 0s
+	// 1 100ms
+	// stopped
+}
+
+func ExampleMaxElapsedTime() {
+	clock := iterstest.NewClock(time.Now())
+	count := 0
+	for range iters.MaxElapsedTimeWithClock(iters.Repeat(10), time.Millisecond*100, clock) {
+		clock.Advance(time.Millisecond * 10)
+		count++
+	}
+	fmt.Println(count)
+
+	// Output:
+	// 11
+}
+
+func TestRetryAfterDelay_stopTimer(t *testing.T) {
+	t.Parallel()
+
+	clock := iterstest.NewClock(time.Now())
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		iters.Count2(iters.RetryAfterDelay(ctx, iters.Repeat(time.Second), iters.WithClock(clock)))
+		close(done)
+	}()
+	clock.BlockUntil(1)
+	cancel()
+	<-done
+	if clock.Timers() != 0 {
+		t.Errorf("expected no active timers, actual: %d", clock.Timers())
+	}
+}
+
+func TestRetry_deadlineWithClock(t *testing.T) {
+	t.Parallel()
+
+	// The fake clock is far behind the context deadline, the remaining time is still measured by the system clock.
+	clock := iterstest.NewClock(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	var outcome iters.RetryOutcome
+	n := iters.Count2(iters.Retry(ctx, iters.Repeat(time.Hour*2), iters.WithClock(clock), iters.WithDeadlineStop(),
+		iters.WithOutcome(&outcome)))
+	if n != 1 || outcome.Reason != iters.StopDeadline {
+		t.Errorf("unexpected result: %d attempts, %s", n, outcome.Reason)
+	}
+}
+
+func TestWithMaxElapsedTime(t *testing.T) {
+	t.Parallel()
+
+	// The elapsed time is measured from the first delay, the immediate attempt is not counted.
+	clock := iterstest.NewClock(time.Now())
+	var outcome iters.RetryOutcome
+	attempts := 0
+	for range iters.Retry(context.Background(), iters.Repeat(time.Duration(0)), iters.WithClock(clock),
+		iters.WithMaxElapsedTime(time.Millisecond*100), iters.WithOutcome(&outcome)) {
+		attempts++
+		clock.Advance(time.Millisecond * 150)
+	}
+	if attempts != 2 || outcome.Reason != iters.StopMaxElapsed {
+		t.Errorf("unexpected result: %d attempts, %s", attempts, outcome.Reason)
+	}
+}
+
+func TestWithMaxElapsedTime_outcome(t *testing.T) {
+	t.Parallel()
+
+	clock := iterstest.NewClock(time.Now())
+	var outcome iters.RetryOutcome
+	done := make(chan int)
+	go func() {
+		done <- iters.Count2(iters.RetryAfterDelay(context.Background(), iters.Repeat(time.Millisecond*10),
+			iters.WithClock(clock), iters.WithMaxElapsedTime(time.Millisecond*30), iters.WithOutcome(&outcome)))
+	}()
+	for range 3 {
+		clock.BlockUntil(1)
+		clock.Advance(time.Millisecond * 10)
+	}
+	if n := <-done; n != 3 {
+		t.Errorf("unexpected attempts: %d", n)
+	}
+	if outcome.Reason != iters.StopMaxElapsed || !errors.Is(outcome.Err, iters.ErrMaxElapsedTime) ||
+		outcome.Waited != time.Millisecond*30 {
+		t.Errorf("unexpected outcome: %+v", outcome)
+	}
+}
+
+func TestWithMaxElapsedTime_hint(t *testing.T) {
+	t.Parallel()
+
+	// The fake clock is never advanced, so waiting for the hinted delay would block until the context deadline.
+	clock := iterstest.NewClock(time.Now())
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	var outcome iters.RetryOutcome
+	err := iters.Do(ctx, iters.Repeat(time.Millisecond), func(context.Context) error {
+		return iters.RetryAfter(errors.New("throttled"), time.Hour)
+	}, iters.WithClock(clock), iters.WithMaxElapsedTime(time.Millisecond*100), iters.WithOutcome(&outcome))
+	if !errors.Is(err, iters.ErrMaxElapsedTime) || outcome.Reason != iters.StopMaxElapsed || outcome.Attempts != 1 {
+		t.Errorf("unexpected result: %v, %+v", err, outcome)
+	}
+}
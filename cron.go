@@ -0,0 +1,277 @@
+package iters
+
+import (
+	"fmt"
+	"iter"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cron is a schedule defined by a cron expression.
+type Cron struct {
+	expr    string
+	loc     *time.Location
+	minute  uint64
+	hour    uint64
+	dom     uint64
+	month   uint64
+	dow     uint64
+	domStar bool
+	dowStar bool
+	// hourly reports that every hour matches, so the schedule is an interval within an hour.
+	hourly bool
+}
+
+type cronField struct {
+	name     string
+	min, max int
+	names    []string // names of values starting with min
+}
+
+var (
+	cronMinute = cronField{name: "minute", min: 0, max: 59}
+	cronHour   = cronField{name: "hour", min: 0, max: 23}
+	cronDom    = cronField{name: "day of month", min: 1, max: 31}
+	cronMonth  = cronField{
+		name: "month", min: 1, max: 12,
+		names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"},
+	}
+	// Day of week 7 is Sunday, as 0.
+	cronDow = cronField{name: "day of week", min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}}
+)
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseCron parses a cron expression of five fields: minute, hour, day of month, month and day of week.
+// Fields support "*", values, ranges "a-b", steps "*/s" and "a-b/s", lists "a,b" and names of months and
+// days of week. If both day of month and day of week are restricted, a day matching either of them matches.
+// The descriptors @yearly, @annually, @monthly, @weekly, @daily, @midnight and @hourly are supported.
+// The time zone of the schedule can be set with the "CRON_TZ=" or "TZ=" prefix, for example,
+// "CRON_TZ=Europe/Berlin 0 9 * * 1-5". By default, the schedule is in the local time zone.
+func ParseCron(expr string) (*Cron, error) {
+	c := &Cron{expr: strings.TrimSpace(expr), loc: time.Local}
+
+	spec := c.expr
+	if strings.HasPrefix(spec, "CRON_TZ=") || strings.HasPrefix(spec, "TZ=") {
+		tz, rest, _ := strings.Cut(spec, " ")
+		_, name, _ := strings.Cut(tz, "=")
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			return nil, fmt.Errorf("iters: invalid cron time zone %q: %w", name, err)
+		}
+		c.loc = loc
+		spec = strings.TrimSpace(rest)
+	}
+	if d, ok := cronDescriptors[spec]; ok {
+		spec = d
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("iters: invalid cron expression %q: expected 5 fields, got %d", expr, len(fields))
+	}
+	var err error
+	for i, p := range []struct {
+		bits  *uint64
+		field cronField
+	}{
+		{&c.minute, cronMinute},
+		{&c.hour, cronHour},
+		{&c.dom, cronDom},
+		{&c.month, cronMonth},
+		{&c.dow, cronDow},
+	} {
+		if *p.bits, err = parseCronField(fields[i], p.field); err != nil {
+			return nil, fmt.Errorf("iters: invalid cron expression %q: %w", expr, err)
+		}
+	}
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	c.domStar = fields[2] == "*" || fields[2] == "?"
+	c.dowStar = fields[4] == "*" || fields[4] == "?"
+	c.hourly = c.hour == 1<<24-1
+	return c, nil
+}
+
+// parseCronField parses a field into the bit set of matching values.
+func parseCronField(s string, f cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(s, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepStr); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid %s step %q", f.name, stepStr)
+			}
+		}
+
+		var lo, hi int
+		switch {
+		case rng == "*" || rng == "?":
+			lo, hi = f.min, f.max
+		default:
+			loStr, hiStr, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = f.value(loStr); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if isRange {
+				if hi, err = f.value(hiStr); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				hi = f.max
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid %s range %q", f.name, rng)
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// value parses a value or a name of the field.
+func (f cronField) value(s string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(s, name) {
+			return f.min + i, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid %s %q", f.name, s)
+	}
+	return v, nil
+}
+
+// String returns the cron expression.
+func (c *Cron) String() string {
+	return c.expr
+}
+
+// Next returns the first time of the schedule after t, in the location of t.
+// Wall clock times skipped by a DST transition are skipped by the schedule.
+// Wall clock times repeated by a DST transition are scheduled once if the hour is restricted, for example,
+// a daily job runs once. If every hour matches, the repeated times are scheduled as they come in absolute time,
+// so intervals, such as every 15 minutes, have no gap.
+// Returns the zero time if there is no such time within five years.
+func (c *Cron) Next(t time.Time) time.Time {
+	if c.hourly {
+		return c.next(t)
+	}
+	after := wallClock(t.In(c.loc))
+	for {
+		t = c.next(t)
+		if t.IsZero() || wallClock(t.In(c.loc)).After(after) {
+			return t
+		}
+	}
+}
+
+// wallClock returns the wall clock time of t as UTC time.
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+func (c *Cron) next(t time.Time) time.Time {
+	orig := t.Location()
+	t = t.In(c.loc)
+	// Time zone offsets are whole minutes, so truncation keeps the wall clock minute.
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Year() + 5
+
+wrap:
+	if t.Year() > limit {
+		return time.Time{}
+	}
+	for !has(c.month, int(t.Month())) {
+		t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, c.loc)
+		if t.Month() == time.January {
+			goto wrap
+		}
+	}
+	for !c.dayMatches(t) {
+		t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, c.loc)
+		if t.Day() == 1 {
+			goto wrap
+		}
+	}
+	for !has(c.hour, t.Hour()) {
+		// Adding absolute time instead of building a wall clock time passes ambiguous DST hours.
+		t = t.Add(time.Hour - time.Duration(t.Minute())*time.Minute)
+		if t.Hour() == 0 {
+			goto wrap
+		}
+	}
+	for !has(c.minute, t.Minute()) {
+		t = t.Add(time.Minute)
+		if t.Minute() == 0 {
+			goto wrap
+		}
+	}
+	return t.In(orig)
+}
+
+// dayMatches reports whether the day of t matches the day of month and the day of week.
+func (c *Cron) dayMatches(t time.Time) bool {
+	dom := has(c.dom, t.Day())
+	dow := has(c.dow, int(t.Weekday()))
+	if c.domStar || c.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+func has(bits uint64, v int) bool {
+	return bits&(1<<uint(v)) != 0
+}
+
+// Times returns the sequence of times of the schedule after from.
+func (c *Cron) Times(from time.Time) iter.Seq[time.Time] {
+	return func(yield func(time.Time) bool) {
+		for t := c.Next(from); !t.IsZero(); t = c.Next(t) {
+			if !yield(t) {
+				return
+			}
+		}
+	}
+}
+
+// DelaysUntil converts the sequence of times to the sequence of delays until these times,
+// so schedules can be used with RetryAfterDelay. The delay is computed when the time is pulled.
+// Times in the past are skipped.
+func DelaysUntil(times iter.Seq[time.Time]) iter.Seq[time.Duration] {
+	return DelaysUntilWithClock(times, systemClock{})
+}
+
+// DelaysUntilWithClock converts the sequence of times to the sequence of delays until these times,
+// measured by the clock.
+func DelaysUntilWithClock(times iter.Seq[time.Time], clock Clock) iter.Seq[time.Duration] {
+	clock = clockOrSystem(clock)
+	return func(yield func(time.Duration) bool) {
+		for t := range times {
+			d := t.Sub(clock.Now())
+			if d < 0 {
+				continue
+			}
+			if !yield(d) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package iters
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+func ExampleParseCron() {
+	cron, err := ParseCron("CRON_TZ=Europe/Berlin 0 9 * * mon-fri")
+	if err != nil {
+		panic(err)
+	}
+	from := time.Date(2024, 3, 29, 10, 0, 0, 0, time.UTC) // Friday.
+	for t := range Trim(cron.Times(from), 3) {
+		fmt.Println(t)
+	}
+
+	// Output:
+	// 2024-04-01 07:00:00 +0000 UTC
+	// 2024-04-02 07:00:00 +0000 UTC
+	// 2024-04-03 07:00:00 +0000 UTC
+}
+
+func TestCron(t *testing.T) {
+	t.Parallel()
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		expr     string
+		from     time.Time
+		expected []string
+	}{
+		{
+			"*/15 * * * *",
+			time.Date(2024, 1, 1, 10, 7, 30, 0, time.UTC),
+			[]string{"2024-01-01T10:15:00Z", "2024-01-01T10:30:00Z", "2024-01-01T10:45:00Z", "2024-01-01T11:00:00Z"},
+		},
+		{
+			"@hourly",
+			time.Date(2024, 1, 1, 23, 0, 0, 0, time.UTC),
+			[]string{"2024-01-02T00:00:00Z", "2024-01-02T01:00:00Z"},
+		},
+		{
+			"0 0 29 feb *",
+			time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			[]string{"2024-02-29T00:00:00Z", "2028-02-29T00:00:00Z"},
+		},
+		{
+			"0 0 13 * 5",
+			time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC),
+			[]string{"2024-09-06T00:00:00Z", "2024-09-13T00:00:00Z", "2024-09-20T00:00:00Z"},
+		},
+		{
+			"0 12 * * 7",
+			time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC),
+			[]string{"2024-09-01T12:00:00Z", "2024-09-08T12:00:00Z"},
+		},
+		{
+			"0 0 1-10/3 1,jun *",
+			time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC),
+			[]string{"2024-01-07T00:00:00Z", "2024-01-10T00:00:00Z", "2024-06-01T00:00:00Z"},
+		},
+		{
+			// 02:30 does not exist on 2024-03-31 in Berlin.
+			"CRON_TZ=Europe/Berlin 30 2 * * *",
+			time.Date(2024, 3, 30, 12, 0, 0, 0, berlin),
+			[]string{"2024-04-01T02:30:00+02:00", "2024-04-02T02:30:00+02:00"},
+		},
+		{
+			// 02:30 occurs twice on 2024-10-27 in Berlin.
+			"CRON_TZ=Europe/Berlin 30 2 * * *",
+			time.Date(2024, 10, 26, 12, 0, 0, 0, berlin),
+			[]string{"2024-10-27T02:30:00+02:00", "2024-10-28T02:30:00+01:00"},
+		},
+		{
+			// Intervals continue through the repeated hour in absolute time.
+			"TZ=Europe/Berlin 0 * * * *",
+			time.Date(2024, 10, 27, 1, 30, 0, 0, berlin),
+			[]string{"2024-10-27T02:00:00+02:00", "2024-10-27T02:00:00+01:00", "2024-10-27T03:00:00+01:00"},
+		},
+		{
+			"CRON_TZ=Europe/Berlin */15 * * * *",
+			time.Date(2024, 10, 27, 0, 40, 0, 0, time.UTC).In(berlin), // 02:40 CEST.
+			[]string{
+				"2024-10-27T02:45:00+02:00", "2024-10-27T02:00:00+01:00", "2024-10-27T02:15:00+01:00",
+				"2024-10-27T02:30:00+01:00", "2024-10-27T02:45:00+01:00", "2024-10-27T03:00:00+01:00",
+			},
+		},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			cron, err := ParseCron(tc.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual := slices.Collect(Map(Trim(cron.Times(tc.from), len(tc.expected)), func(t time.Time) string {
+				return t.Format(time.RFC3339)
+			}))
+			assertEquals(t, fmt.Sprint(tc.expected), fmt.Sprint(actual))
+		})
+	}
+
+	for _, expr := range []string{
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"* * * foo *",
+		"CRON_TZ=Nowhere/Never * * * * *",
+	} {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := ParseCron(expr); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+type fixedClock struct {
+	Clock
+	now time.Time
+}
+
+func (c fixedClock) Now() time.Time {
+	return c.now
+}
+
+func TestDelaysUntil(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 10, 7, 0, 0, time.UTC)
+	cron, err := ParseCron("*/15 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	times := Merge(Of(now.Add(-time.Minute)), cron.Times(now))
+	delays := slices.Collect(Trim(DelaysUntilWithClock(times, fixedClock{now: now}), 2))
+	assertEquals(t, fmt.Sprint([]time.Duration{8 * time.Minute, 23 * time.Minute}), fmt.Sprint(delays))
+}
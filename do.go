@@ -0,0 +1,112 @@
+package iters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"time"
+)
+
+// AttemptError is an error of a single attempt made by Do or DoValue.
+type AttemptError struct {
+	// Attempt is the attempt number, starting with 0.
+	Attempt int
+	// Delay is the delay waited before the attempt.
+	Delay time.Duration
+	// Err is the error returned by the operation.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *AttemptError) Error() string {
+	if e.Attempt == 0 {
+		return fmt.Sprintf("attempt %d: %v", e.Attempt, e.Err)
+	}
+	return fmt.Sprintf("attempt %d after %s: %v", e.Attempt, e.Delay, e.Err)
+}
+
+// Unwrap returns the error returned by the operation.
+func (e *AttemptError) Unwrap() error {
+	return e.Err
+}
+
+// Do calls the operation until it succeeds, retrying with the specified delays.
+// The first call occurs immediately, the following calls happen after the delays, as in Retry.
+// If all attempts fail, the returned error joins the errors of all attempts wrapped into AttemptError
+// and the context error if the context was cancelled.
+// Retrying stops early if the operation returns a permanent error or the classifier decides so.
+func Do(ctx context.Context, delays iter.Seq[time.Duration], op func(ctx context.Context) error, opts ...RetryOption) error {
+	_, err := DoValue(ctx, delays, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, op(ctx)
+	}, opts...)
+	return err
+}
+
+// DoValue calls the operation until it succeeds and returns the value of the first successful call.
+// The first call occurs immediately, the following calls happen after the delays, as in Retry.
+// If all attempts fail, the returned error joins the errors of all attempts wrapped into AttemptError
+// and the error of the stop reason (see RetryOutcome).
+// Retrying stops early if the operation returns a permanent error or the classifier decides so.
+// Only the errors that are retried are recorded as failures in the circuit breaker.
+func DoValue[T any](
+	ctx context.Context, delays iter.Seq[time.Duration], op func(ctx context.Context) (T, error), opts ...RetryOption,
+) (T, error) {
+	cfg := newRetryConfig(opts)
+	l := retryLoop{ctx: ctx, cfg: &cfg}
+	var hints DelayHints
+	var errs []error
+	for attempt, delay := range l.seq(hints.Seq(delays), true) {
+		v, err := callAttempt(ctx, cfg.attemptTimeout, op)
+		if err == nil {
+			if cfg.breaker != nil {
+				cfg.breaker.Success()
+			}
+			if cfg.budget != nil {
+				cfg.budget.Success()
+			}
+			l.stop(StopSucceeded, nil)
+			return v, nil
+		}
+		errs = append(errs, &AttemptError{Attempt: attempt, Delay: delay, Err: err})
+		l.lastErr = err
+		// Only retryable errors are failures of the dependency. The cancelled context and non-retryable errors
+		// are errors of the caller, they are not recorded in the breaker.
+		if ctx.Err() != nil {
+			l.release()
+			l.stop(StopCanceled, ctx.Err())
+			break
+		}
+		if IsPermanent(err) {
+			l.release()
+			l.stop(StopNonRetryable, nil)
+			break
+		}
+		decision := cfg.classifier(err)
+		if decision.Stop {
+			l.release()
+			l.stop(StopNonRetryable, nil)
+			break
+		}
+		if cfg.breaker != nil {
+			cfg.breaker.Failure()
+		}
+		if decision.After > 0 {
+			hints.Hint(decision.After)
+		}
+	}
+	if l.outcome.Err != nil {
+		errs = append(errs, l.outcome.Err)
+	}
+
+	var zero T
+	return zero, errors.Join(errs...)
+}
+
+// callAttempt calls the operation with the attempt context.
+func callAttempt[T any](ctx context.Context, timeout time.Duration, op func(ctx context.Context) (T, error)) (T, error) {
+	ctx, cancel := attemptContext(ctx, timeout)
+	defer cancel()
+
+	return op(ctx)
+}
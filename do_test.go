@@ -0,0 +1,82 @@
+package iters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+)
+
+func ExampleDoValue() {
+	calls := 0
+	v, err := DoValue(context.Background(), Trim(Repeat(time.Millisecond), 5), func(context.Context) (int, error) {
+		calls++
+		if calls < 3 {
+			return 0, io.ErrUnexpectedEOF
+		}
+		return calls * 10, nil
+	})
+	fmt.Println(v, err)
+
+	// Output:
+	// 30 <nil>
+}
+
+func ExampleDo() {
+	err := Do(context.Background(), Of(time.Millisecond, 2*time.Millisecond), func(context.Context) error {
+		return io.EOF
+	})
+	fmt.Println(err)
+	fmt.Println(errors.Is(err, io.EOF))
+
+	// Output:
+	// attempt 0: EOF
+	// attempt 1 after 1ms: EOF
+	// attempt 2 after 2ms: EOF
+	// true
+}
+
+func TestDo(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		calls := 0
+		err := Do(context.Background(), Repeat(time.Duration(0)), func(context.Context) error {
+			calls++
+			if calls == 5 {
+				return nil
+			}
+			return io.EOF
+		})
+		assertEquals(t, nil, err)
+		assertEquals(t, 5, calls)
+	})
+
+	t.Run("attempt errors", func(t *testing.T) {
+		err := Do(context.Background(), Trim(Repeat(time.Duration(0)), 2), func(context.Context) error {
+			return io.EOF
+		})
+		var attemptErr *AttemptError
+		if !errors.As(err, &attemptErr) {
+			t.Fatalf("expected AttemptError, actual: %v", err)
+		}
+		assertEquals(t, 0, attemptErr.Attempt)
+		assertEquals(t, 3, len(err.(interface{ Unwrap() []error }).Unwrap()))
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		err := Do(ctx, Repeat(time.Hour), func(context.Context) error {
+			cancel()
+			return io.EOF
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, actual: %v", err)
+		}
+		if !errors.Is(err, io.EOF) {
+			t.Errorf("expected io.EOF, actual: %v", err)
+		}
+	})
+}
@@ -0,0 +1,119 @@
+package iters
+
+import (
+	"iter"
+)
+
+// FanIn merges sequences of values into one, consuming all sequences concurrently in goroutines
+// and yielding values as they arrive. When the consumer stops the iteration, producers are stopped at their
+// next value and the iteration returns without waiting for them, so an idle sequence does not block the consumer.
+// If a producer panics, the other producers are stopped and the panic is propagated to the consumer.
+// A panic after the consumer stopped the iteration is not recovered, as in any other goroutine.
+func FanIn[V any](seqs ...iter.Seq[V]) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		fanIn(seqs, yield)
+	}
+}
+
+// FanIn2 merges sequences of key-value pairs into one, consuming all sequences concurrently in goroutines
+// and yielding pairs as they arrive. When the consumer stops the iteration, producers are stopped at their
+// next pair and the iteration returns without waiting for them, so an idle sequence does not block the consumer.
+// If a producer panics, the other producers are stopped and the panic is propagated to the consumer.
+// A panic after the consumer stopped the iteration is not recovered, as in any other goroutine.
+func FanIn2[K, V any](seqs ...iter.Seq2[K, V]) iter.Seq2[K, V] {
+	type pair struct {
+		k K
+		v V
+	}
+	return func(yield func(K, V) bool) {
+		pairs := make([]iter.Seq[pair], len(seqs))
+		for i, seq := range seqs {
+			pairs[i] = func(yield func(pair) bool) {
+				for k, v := range seq {
+					if !yield(pair{k: k, v: v}) {
+						return
+					}
+				}
+			}
+		}
+		fanIn(pairs, func(p pair) bool {
+			return yield(p.k, p.v)
+		})
+	}
+}
+
+// producerExit is the result of a producer goroutine.
+type producerExit struct {
+	panicked bool
+	value    any
+}
+
+// produce iterates the sequence in a new goroutine and passes its values to send until it returns false.
+// Then it calls finish, if it is not nil, and reports the exit to exits, unless done is closed.
+// Stages do not wait for producers after they are done, because a producer may be blocked in the sequence.
+// So a producer that panics after done is closed re-panics, there is no consumer to propagate the panic to.
+func produce[V any](seq iter.Seq[V], send func(V) bool, finish func(), exits chan<- producerExit, done <-chan struct{}) {
+	go func() {
+		exit := producerExit{panicked: true}
+		defer func() {
+			if exit.panicked {
+				exit.value = recover()
+			}
+			if finish != nil {
+				finish()
+			}
+			select {
+			case <-done:
+			default:
+				select {
+				case exits <- exit:
+					return
+				case <-done:
+				}
+			}
+			if exit.panicked {
+				panic(exit.value)
+			}
+		}()
+
+		for v := range seq {
+			if !send(v) {
+				break
+			}
+		}
+		exit.panicked = false
+	}()
+}
+
+func fanIn[V any](seqs []iter.Seq[V], yield func(V) bool) {
+	done := make(chan struct{})
+	defer close(done)
+
+	values := make(chan V)
+	exits := make(chan producerExit)
+	send := func(v V) bool {
+		select {
+		case values <- v:
+			return true
+		case <-done:
+			return false
+		}
+	}
+	for _, seq := range seqs {
+		produce(seq, send, nil, exits, done)
+	}
+
+	for running := len(seqs); running > 0; {
+		select {
+		case v := <-values:
+			if !yield(v) {
+				return
+			}
+		case exit := <-exits:
+			running--
+			if exit.panicked {
+				panic(exit.value)
+			}
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package iters
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+	"testing"
+	"time"
+)
+
+func ExampleFanIn() {
+	slow := func(yield func(string) bool) {
+		time.Sleep(time.Millisecond * 50)
+		yield("slow")
+	}
+	fast := Of("fast")
+	fmt.Println(slices.Collect(FanIn(slow, fast)))
+
+	// Output:
+	// [fast slow]
+}
+
+func TestFanIn(t *testing.T) {
+	t.Parallel()
+
+	t.Run("all values", func(t *testing.T) {
+		t.Parallel()
+
+		actual := slices.Sorted(FanIn(
+			slices.Values([]int{1, 4, 7}),
+			slices.Values([]int{2, 5, 8}),
+			slices.Values([]int{3, 6, 9}),
+			Of[int](),
+		))
+		assertEquals(t, fmt.Sprint([]int{1, 2, 3, 4, 5, 6, 7, 8, 9}), fmt.Sprint(actual))
+		assertEquals(t, 0, Count(FanIn[int]()))
+	})
+
+	t.Run("break", func(t *testing.T) {
+		t.Parallel()
+
+		stopped := make(chan struct{})
+		n := 0
+		for range FanIn(infinite(stopped), infinite(stopped), infinite(stopped)) {
+			n++
+			if n == 100 {
+				break
+			}
+		}
+		for range 3 {
+			<-stopped
+		}
+	})
+
+	t.Run("idle", func(t *testing.T) {
+		t.Parallel()
+
+		ch := make(chan int)
+		stopped := make(chan struct{})
+		idle := func(yield func(int) bool) {
+			defer close(stopped)
+			for v := range FromChan(ch) {
+				if !yield(v) {
+					return
+				}
+			}
+		}
+		// The iteration returns while the idle producer is blocked, it returns when the sequence ends.
+		for range FanIn(Of(1), idle) {
+			break
+		}
+		close(ch)
+		<-stopped
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		t.Parallel()
+
+		stopped := make(chan struct{})
+		defer func() {
+			assertEquals(t, "producer failed", recover())
+			<-stopped
+		}()
+		failing := func(yield func(int) bool) {
+			yield(1)
+			panic("producer failed")
+		}
+		Count(FanIn(infinite(stopped), failing))
+		t.Error("panic is not propagated")
+	})
+}
+
+// infinite returns an infinite sequence that sends to stopped when it returns.
+func infinite(stopped chan<- struct{}) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		defer func() { stopped <- struct{}{} }()
+		for i := 0; ; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+func TestFanIn2(t *testing.T) {
+	t.Parallel()
+
+	var actual []string
+	for k, v := range FanIn2(slices.All([]string{"a", "b"}), slices.All([]string{"c"})) {
+		actual = append(actual, fmt.Sprintf("%d%s", k, v))
+	}
+	slices.Sort(actual)
+	assertEquals(t, fmt.Sprint([]string{"0a", "0c", "1b"}), fmt.Sprint(actual))
+}
@@ -0,0 +1,128 @@
+package iters
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"time"
+)
+
+// Hedge calls the operation and launches additional speculative attempts after the delays, without cancelling
+// the attempts in flight. It returns the value of the first successful attempt and cancels the rest.
+// Failed attempts do not hasten the next one, it is launched after its delay anyway.
+// Hedging stops early if an attempt returns a permanent error or the classifier decides so.
+// If all attempts fail, the returned error joins the errors of all attempts wrapped into AttemptError
+// and the context error if the context was cancelled.
+func Hedge[T any](
+	ctx context.Context, delays iter.Seq[time.Duration], op func(ctx context.Context) (T, error), opts ...HedgeOption,
+) (T, error) {
+	cfg := hedgeConfig{
+		classifier: DefaultClassifier,
+		clock:      systemClock{},
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		v   T
+		err error
+	}
+	results := make(chan result)
+	var errs []error
+	inFlight := 0
+	launch := func(attempt int, delay time.Duration) {
+		inFlight++
+		go func() {
+			v, err := op(ctx)
+			if err != nil {
+				err = &AttemptError{Attempt: attempt, Delay: delay, Err: err}
+			}
+			select {
+			case results <- result{v: v, err: err}:
+			case <-ctx.Done():
+			}
+		}()
+	}
+
+	if delays == nil {
+		delays = Of[time.Duration]()
+	}
+	next, stop := iter.Pull(delays)
+	defer stop()
+
+	var timer Timer
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+	var timerC <-chan time.Time
+	attempt := 0
+	var delay time.Duration
+	// schedule pulls the next delay and starts the timer. The timer channel is nil if delays are exhausted.
+	schedule := func() {
+		var ok bool
+		if delay, ok = next(); !ok {
+			timerC = nil
+			return
+		}
+		if timer == nil {
+			timer = cfg.clock.NewTimer(delay)
+		} else {
+			timer.Reset(delay)
+		}
+		timerC = timer.C()
+	}
+
+	launch(attempt, 0)
+	schedule()
+	for {
+		select {
+		case r := <-results:
+			inFlight--
+			if r.err == nil {
+				return r.v, nil
+			}
+			errs = append(errs, r.err)
+			if IsPermanent(r.err) || cfg.classifier(r.err).Stop || (inFlight == 0 && timerC == nil) {
+				var zero T
+				return zero, errors.Join(errs...)
+			}
+		case <-timerC:
+			attempt++
+			launch(attempt, delay)
+			schedule()
+		case <-ctx.Done():
+			var zero T
+			return zero, errors.Join(append(errs, ctx.Err())...)
+		}
+	}
+}
+
+// HedgeOption configures hedging.
+type HedgeOption func(*hedgeConfig)
+
+type hedgeConfig struct {
+	classifier Classifier
+	clock      Clock
+}
+
+// WithHedgeClassifier sets the classifier of attempt errors. Permanent errors are always terminal,
+// regardless of the classifier.
+func WithHedgeClassifier(c Classifier) HedgeOption {
+	return func(cfg *hedgeConfig) {
+		if c != nil {
+			cfg.classifier = c
+		}
+	}
+}
+
+// WithHedgeClock sets the clock used to wait for delays. By default, the system clock is used.
+func WithHedgeClock(c Clock) HedgeOption {
+	return func(cfg *hedgeConfig) {
+		cfg.clock = clockOrSystem(c)
+	}
+}
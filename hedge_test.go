@@ -0,0 +1,120 @@
+package iters_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gotidy/iters"
+	"github.com/gotidy/iters/iterstest"
+)
+
+func ExampleHedge() {
+	var calls atomic.Int32
+	v, err := iters.Hedge(context.Background(), iters.Repeat(time.Millisecond*10), func(ctx context.Context) (string, error) {
+		if calls.Add(1) == 1 {
+			// The first attempt hangs.
+			<-ctx.Done()
+			return "", ctx.Err()
+		}
+		return "hedged", nil
+	})
+	fmt.Println(v, err)
+
+	// Output:
+	// hedged <nil>
+}
+
+func TestHedge(t *testing.T) {
+	t.Parallel()
+
+	t.Run("cancel the rest", func(t *testing.T) {
+		cancelled := make(chan struct{})
+		var calls atomic.Int32
+		v, err := iters.Hedge(context.Background(), iters.Repeat(time.Millisecond), func(ctx context.Context) (int, error) {
+			n := calls.Add(1)
+			if n == 1 {
+				<-ctx.Done()
+				close(cancelled)
+				return 0, ctx.Err()
+			}
+			return int(n), nil
+		})
+		if err != nil || v != 2 {
+			t.Errorf("unexpected result: %d, %v", v, err)
+		}
+		<-cancelled
+	})
+
+	t.Run("all failed", func(t *testing.T) {
+		clock := iterstest.NewClock(time.Now())
+		var calls atomic.Int32
+		var err error
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, err = iters.Hedge(context.Background(), iters.Trim(iters.Repeat(time.Hour), 2), func(context.Context) (int, error) {
+				calls.Add(1)
+				return 0, io.EOF
+			}, iters.WithHedgeClock(clock))
+		}()
+
+		// Failed attempts do not launch the next one before its delay.
+		for range 2 {
+			clock.BlockUntil(1)
+			select {
+			case <-done:
+				t.Fatalf("the next attempt is launched before the delay, calls: %d", calls.Load())
+			case <-time.After(time.Millisecond * 10):
+			}
+			clock.Advance(time.Hour)
+		}
+		<-done
+		if !errors.Is(err, io.EOF) {
+			t.Errorf("expected io.EOF, actual: %v", err)
+		}
+		if n := len(err.(interface{ Unwrap() []error }).Unwrap()); n != 3 || calls.Load() != 3 {
+			t.Errorf("unexpected attempts: %d errors, %d calls", n, calls.Load())
+		}
+	})
+
+	t.Run("permanent", func(t *testing.T) {
+		var calls atomic.Int32
+		_, err := iters.Hedge(context.Background(), iters.Repeat(time.Hour), func(context.Context) (int, error) {
+			calls.Add(1)
+			return 0, iters.Permanent(io.EOF)
+		}, iters.WithHedgeClock(iterstest.NewClock(time.Now())))
+		if !iters.IsPermanent(err) || calls.Load() != 1 {
+			t.Errorf("unexpected result: %v, %d calls", err, calls.Load())
+		}
+	})
+
+	t.Run("classifier", func(t *testing.T) {
+		var calls atomic.Int32
+		_, err := iters.Hedge(context.Background(), iters.Repeat(time.Hour), func(context.Context) (int, error) {
+			calls.Add(1)
+			return 0, io.EOF
+		}, iters.WithHedgeClock(iterstest.NewClock(time.Now())), iters.WithHedgeClassifier(func(error) iters.RetryDecision {
+			return iters.RetryDecisionStop
+		}))
+		if !errors.Is(err, io.EOF) || calls.Load() != 1 {
+			t.Errorf("unexpected result: %v, %d calls", err, calls.Load())
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+		defer cancel()
+		_, err := iters.Hedge(ctx, nil, func(ctx context.Context) (int, error) {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected context.DeadlineExceeded, actual: %v", err)
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package iters
+
+import (
+	"iter"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DelayHints overrides the next delay of a delays sequence with a hint, for example,
+// with the delay requested by a server. A hint replaces the next value of the sequence,
+// so wrapping sequences, such as Trim, and the retry options are still respected.
+// Only WithMaxElapsedTime bounds hinted delays by the elapsed time, MaxElapsedTime does not bound the delays.
+// DelayHints must not be used concurrently.
+//
+//	var hints DelayHints
+//	for range Retry(ctx, hints.Seq(Exponential(time.Second, time.Minute, 2)), WithMaxElapsedTime(time.Hour)) {
+//		resp, err := client.Do(req)
+//		...
+//		if d, ok := ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+//			hints.Hint(d)
+//		}
+//	}
+type DelayHints struct {
+	delay time.Duration
+	set   bool
+}
+
+// Hint replaces the next delay.
+func (h *DelayHints) Hint(d time.Duration) {
+	h.delay = d
+	h.set = true
+}
+
+// Seq returns the delays sequence with the next delay replaced if it was hinted.
+func (h *DelayHints) Seq(delays iter.Seq[time.Duration]) iter.Seq[time.Duration] {
+	if delays == nil {
+		return nil
+	}
+	return func(yield func(time.Duration) bool) {
+		for d := range delays {
+			if h.set {
+				d, h.set = h.delay, false
+			}
+			if !yield(d) {
+				return
+			}
+		}
+	}
+}
+
+// retryAfterLayouts are the HTTP date formats.
+var retryAfterLayouts = []string{
+	"Mon, 02 Jan 2006 15:04:05 GMT",
+	time.RFC850,
+	time.ANSIC,
+}
+
+// ParseRetryAfter parses the value of the Retry-After HTTP header, either delay seconds or HTTP date,
+// and returns the delay relative to now. A date in the past results in zero delay.
+func ParseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(min(seconds, math.MaxInt64/int64(time.Second))) * time.Second, true
+	}
+	for _, layout := range retryAfterLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return max(0, t.Sub(now)), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,80 @@
+package iters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+func ExampleDelayHints() {
+	var hints DelayHints
+	for attempt, delay := range Retry(context.Background(), hints.Seq(Trim(Repeat(time.Millisecond), 3))) {
+		fmt.Println(attempt, delay)
+		if attempt == 1 {
+			// The server requested a delay.
+			hints.Hint(time.Millisecond * 5)
+		}
+	}
+
+	// Output:
+	// 0 0s
+	// 1 1ms
+	// 2 5ms
+	// 3 1ms
+}
+
+func ExampleRetryAfter() {
+	errThrottled := errors.New("throttled")
+	err := Do(context.Background(), Trim(Repeat(time.Hour), 1), func(context.Context) error {
+		return RetryAfter(errThrottled, time.Millisecond)
+	})
+	fmt.Println(err)
+
+	// Output:
+	// attempt 0: throttled
+	// attempt 1 after 1ms: throttled
+}
+
+func TestDelayHints(t *testing.T) {
+	t.Parallel()
+
+	var hints DelayHints
+	if hints.Seq(nil) != nil {
+		t.Error("hints of nil sequence are not nil")
+	}
+
+	hints.Hint(time.Hour)
+	seq := Trim(hints.Seq(Of(time.Second, time.Second, time.Second)), 2)
+	assertEquals(t, fmt.Sprint([]time.Duration{time.Hour, time.Second}), fmt.Sprint(slices.Collect(seq)))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2015, 10, 21, 7, 28, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{"120", time.Minute * 2, true},
+		{" 0 ", 0, true},
+		{"Wed, 21 Oct 2015 07:28:30 GMT", time.Second * 30, true},
+		{"Wednesday, 21-Oct-15 07:29:00 UTC", time.Minute, true},
+		{"Wed Oct 21 07:28:10 2015", time.Second * 10, true},
+		{"Wed, 21 Oct 2015 07:00:00 GMT", 0, true},
+		{"99999999999999999999", 0, false},
+		{"-1", 0, false},
+		{"", 0, false},
+		{"soon", 0, false},
+	} {
+		t.Run(tc.value, func(t *testing.T) {
+			d, ok := ParseRetryAfter(tc.value, now)
+			assertEquals(t, tc.expected, d)
+			assertEquals(t, tc.ok, ok)
+		})
+	}
+}
@@ -407,6 +407,55 @@ func Exponential[D constraints.Float | constraints.Integer](start, max D, factor
 	}
 }
 
+// Linear generate linear sequence of values.
+// The first value will be start, each following v = v + step, but not greater than max.
+func Linear[D constraints.Float | constraints.Integer](start, max, step D) iter.Seq[D] {
+	return func(yield func(D) bool) {
+		v := start
+		for {
+			if !yield(v) {
+				return
+			}
+			if v < max {
+				v = min(max, v+step)
+			}
+		}
+	}
+}
+
+// Fibonacci generate sequence of values growing as Fibonacci numbers.
+// The values will be start, start, 2*start, 3*start, 5*start, 8*start..., but not greater than max.
+func Fibonacci[D constraints.Float | constraints.Integer](start, max D) iter.Seq[D] {
+	return func(yield func(D) bool) {
+		var prev D
+		v := start
+		for {
+			if !yield(v) {
+				return
+			}
+			if v < max {
+				prev, v = v, min(max, prev+v)
+			}
+		}
+	}
+}
+
+// Polynomial generate polynomial sequence of values.
+// The n-th value (starting with 1) will be start * n^degree, but not greater than max.
+func Polynomial[D constraints.Float | constraints.Integer](start, max D, degree float64) iter.Seq[D] {
+	return func(yield func(D) bool) {
+		v := start
+		for n := 1; ; n++ {
+			if !yield(v) {
+				return
+			}
+			if v < max {
+				v = D(min(float64(max), float64(start)*math.Pow(float64(n+1), degree)))
+			}
+		}
+	}
+}
+
 func jitter[T constraints.Float | constraints.Integer](v T, factor, random float64) T {
 	if factor == 0 {
 		return v
@@ -423,9 +472,16 @@ func jitter[T constraints.Float | constraints.Integer](v T, factor, random float
 // value = value * (random value in range [1 - Jitter, 1 + Jitter]).
 // Example: jitter of 10 with factor 0.1 will returns values in range [9, 11].
 func Jitter[T constraints.Float | constraints.Integer](vv iter.Seq[T], factor float64) iter.Seq[T] {
+	return JitterWithRand(vv, factor, nil)
+}
+
+// JitterWithRand returns sequence with added jitter to the values, as Jitter does, using the random generator.
+// If r is nil, the global random source is used.
+func JitterWithRand[T constraints.Float | constraints.Integer](vv iter.Seq[T], factor float64, r *rand.Rand) iter.Seq[T] {
+	r = randOrGlobal(r)
 	return func(yield func(T) bool) {
 		jitter := func(v T) T {
-			return jitter(v, factor, float64(rand.Uint64())*2/math.MaxUint64-1) //nolint:gosec
+			return jitter(v, factor, float64(r.Uint64())*2/math.MaxUint64-1)
 		}
 		if factor == 0 {
 			jitter = func(v T) T { return v }
@@ -438,12 +494,83 @@ func Jitter[T constraints.Float | constraints.Integer](vv iter.Seq[T], factor fl
 	}
 }
 
+// FullJitter returns sequence with full jitter applied to the values.
+// value = random value in range [0, value].
+func FullJitter[T constraints.Float | constraints.Integer](vv iter.Seq[T]) iter.Seq[T] {
+	return FullJitterWithRand(vv, nil)
+}
+
+// FullJitterWithRand returns sequence with full jitter applied to the values, using the random generator.
+// If r is nil, the global random source is used.
+func FullJitterWithRand[T constraints.Float | constraints.Integer](vv iter.Seq[T], r *rand.Rand) iter.Seq[T] {
+	r = randOrGlobal(r)
+	return func(yield func(T) bool) {
+		for v := range vv {
+			if !yield(T(float64(v) * r.Float64())) {
+				return
+			}
+		}
+	}
+}
+
+// EqualJitter returns sequence with equal jitter applied to the values.
+// value = value/2 + random value in range [0, value/2].
+func EqualJitter[T constraints.Float | constraints.Integer](vv iter.Seq[T]) iter.Seq[T] {
+	return EqualJitterWithRand(vv, nil)
+}
+
+// EqualJitterWithRand returns sequence with equal jitter applied to the values, using the random generator.
+// If r is nil, the global random source is used.
+func EqualJitterWithRand[T constraints.Float | constraints.Integer](vv iter.Seq[T], r *rand.Rand) iter.Seq[T] {
+	r = randOrGlobal(r)
+	return func(yield func(T) bool) {
+		for v := range vv {
+			half := float64(v) / 2
+			if !yield(T(half + half*r.Float64())) {
+				return
+			}
+		}
+	}
+}
+
+// DecorrelatedJitter returns sequence with decorrelated jitter, where values of the sequence are used as caps.
+// value = min(cap, random value in range [base, previous value * 3]), the first previous value is base.
+// Example: DecorrelatedJitter(Repeat(time.Minute), time.Second).
+func DecorrelatedJitter[T constraints.Float | constraints.Integer](caps iter.Seq[T], base T) iter.Seq[T] {
+	return DecorrelatedJitterWithRand(caps, base, nil)
+}
+
+// DecorrelatedJitterWithRand returns sequence with decorrelated jitter, as DecorrelatedJitter does,
+// using the random generator. If r is nil, the global random source is used.
+func DecorrelatedJitterWithRand[T constraints.Float | constraints.Integer](caps iter.Seq[T], base T, r *rand.Rand) iter.Seq[T] {
+	r = randOrGlobal(r)
+	return func(yield func(T) bool) {
+		prev := base
+		for c := range caps {
+			upper := max(float64(base), float64(prev)*3)
+			v := min(c, T(float64(base)+(upper-float64(base))*r.Float64()))
+			if !yield(v) {
+				return
+			}
+			prev = v
+		}
+	}
+}
+
 // MaxElapsedTime stops sequence processing after the specified time has elapsed.
+// It does not bound the values, so a delay yielded in time may end after it, including a delay hinted by DelayHints.
+// Use WithMaxElapsedTime to bound delays of retries.
 func MaxElapsedTime[T any](seq iter.Seq[T], max time.Duration) iter.Seq[T] {
+	return MaxElapsedTimeWithClock(seq, max, systemClock{})
+}
+
+// MaxElapsedTimeWithClock stops sequence processing after the specified time has elapsed, measured by the clock.
+func MaxElapsedTimeWithClock[T any](seq iter.Seq[T], max time.Duration, clock Clock) iter.Seq[T] {
+	clock = clockOrSystem(clock)
 	return func(yield func(T) bool) {
-		start := time.Now()
+		start := clock.Now()
 		for v := range seq {
-			if time.Since(start) > max {
+			if clock.Now().Sub(start) > max {
 				return
 			}
 			if !yield(v) {
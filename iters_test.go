@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"iter"
 	"math"
+	"math/rand/v2"
 	"slices"
 	"testing"
 	"time"
@@ -444,7 +445,7 @@ func BenchmarkEqual(b *testing.B) {
 	})
 	b.Run("slice equality", func(b *testing.B) {
 		for range b.N {
-			slices.Equal(s, s)
+			_ = slices.Equal(s, s)
 		}
 	})
 }
@@ -540,6 +541,27 @@ func ExampleExponential() {
 	// [1 2 4 8 16 32 64 100 100 100]
 }
 
+func ExampleLinear() {
+	fmt.Println(slices.Collect(Trim(Linear(10, 50, 15), 6)))
+
+	// Output:
+	// [10 25 40 50 50 50]
+}
+
+func ExampleFibonacci() {
+	fmt.Println(slices.Collect(Trim(Fibonacci(time.Second, time.Minute), 12)))
+
+	// Output:
+	// [1s 1s 2s 3s 5s 8s 13s 21s 34s 55s 1m0s 1m0s]
+}
+
+func ExamplePolynomial() {
+	fmt.Println(slices.Collect(Trim(Polynomial(1.0, 50, 2), 9)))
+
+	// Output:
+	// [1 4 9 16 25 36 49 50 50]
+}
+
 func ExampleRepeat() {
 	fmt.Println(slices.Collect(Trim(Repeat(11), 10)))
 
@@ -581,6 +603,70 @@ func TestJitter(t *testing.T) {
 	})
 }
 
+func TestJitterWithRand(t *testing.T) {
+	t.Parallel()
+
+	seq := Trim(Repeat(time.Second), 10)
+	newRand := func() *rand.Rand { return rand.New(rand.NewPCG(1, 2)) }
+
+	for name, jitter := range map[string]func(r *rand.Rand) iter.Seq[time.Duration]{
+		"jitter": func(r *rand.Rand) iter.Seq[time.Duration] { return JitterWithRand(seq, 0.5, r) },
+		"full":   func(r *rand.Rand) iter.Seq[time.Duration] { return FullJitterWithRand(seq, r) },
+		"equal":  func(r *rand.Rand) iter.Seq[time.Duration] { return EqualJitterWithRand(seq, r) },
+		"decorrelated": func(r *rand.Rand) iter.Seq[time.Duration] {
+			return DecorrelatedJitterWithRand(seq, time.Millisecond, r)
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if !Equal(jitter(newRand()), jitter(newRand())) {
+				t.Error("sequences with the same seed are not equal")
+			}
+		})
+	}
+}
+
+func TestFullJitter(t *testing.T) {
+	t.Parallel()
+
+	values := slices.Collect(FullJitterWithRand(Trim(Repeat(20.0), 1000), rand.New(rand.NewPCG(1, 2))))
+	if !isUniform(values, 0, 20) {
+		t.Error("FullJitter is not uniform")
+	}
+}
+
+func TestEqualJitter(t *testing.T) {
+	t.Parallel()
+
+	values := slices.Collect(EqualJitterWithRand(Trim(Repeat(20.0), 1000), rand.New(rand.NewPCG(1, 2))))
+	if !isUniform(values, 10, 20) {
+		t.Error("EqualJitter is not uniform")
+	}
+}
+
+func TestDecorrelatedJitter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("first value", func(t *testing.T) {
+		r := rand.New(rand.NewPCG(1, 2))
+		values := slices.Collect(Map(Trim(Repeat(100.0), 1000), func(c float64) float64 {
+			return slices.Collect(Trim(DecorrelatedJitterWithRand(Repeat(c), 10, r), 1))[0]
+		}))
+		if !isUniform(values, 10, 30) {
+			t.Error("DecorrelatedJitter is not uniform")
+		}
+	})
+
+	t.Run("bounds", func(t *testing.T) {
+		prev := 10.0
+		for v := range Trim(DecorrelatedJitter(Repeat(100.0), 10), 1000) {
+			if v < 10 || v > 100 || v > prev*3 {
+				t.Fatalf("value %v is out of bounds, previous: %v", v, prev)
+			}
+			prev = v
+		}
+	})
+}
+
 func assertEquals[T comparable](t *testing.T, expected, actual T) {
 	if expected != actual {
 		t.Errorf("expected: %v, actual: %v", expected, actual)
@@ -606,14 +692,3 @@ func isUniform(values []float64, minValue, maxValue float64) bool {
 
 	return square < 16.919
 }
-
-func ExampleMaxElapsedTime() {
-	start := time.Now()
-	for range MaxElapsedTime(Repeat(10), time.Millisecond*100) {
-		time.Sleep(time.Millisecond)
-	}
-	fmt.Println(time.Since(start) > time.Millisecond*100)
-
-	// Output:
-	// true
-}
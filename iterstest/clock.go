@@ -0,0 +1,133 @@
+// Package iterstest provides utilities for testing code that uses iters.
+package iterstest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gotidy/iters"
+)
+
+// Clock is a fake iters.Clock. The time of the clock changes only when it is advanced.
+// Clock is safe for concurrent use.
+type Clock struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	now    time.Time
+	timers map[*timer]struct{}
+}
+
+var _ iters.Clock = (*Clock)(nil)
+
+// NewClock creates a fake clock that starts at the specified time.
+func NewClock(now time.Time) *Clock {
+	c := &Clock{
+		now:    now,
+		timers: make(map[*timer]struct{}),
+	}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now returns the current time of the clock.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// NewTimer creates a new timer that fires when the clock is advanced by at least duration d.
+func (c *Clock) NewTimer(d time.Duration) iters.Timer {
+	t := &timer{clock: c, c: make(chan time.Time, 1)}
+	t.Reset(d)
+	return t
+}
+
+// Advance moves the clock forward by the duration and fires expired timers.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	for t := range c.timers {
+		if !t.when.After(c.now) {
+			c.fire(t)
+		}
+	}
+}
+
+// Timers returns the number of active timers.
+func (c *Clock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.timers)
+}
+
+// BlockUntil blocks until the clock has at least n active timers.
+func (c *Clock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for len(c.timers) < n {
+		c.cond.Wait()
+	}
+}
+
+// fire sends the current time to the timer channel and deactivates the timer. Must be called with the lock held.
+func (c *Clock) fire(t *timer) {
+	delete(c.timers, t)
+	select {
+	case t.c <- c.now:
+	default:
+	}
+	c.cond.Broadcast()
+}
+
+type timer struct {
+	clock *Clock
+	c     chan time.Time
+	when  time.Time
+}
+
+func (t *timer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *timer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	return t.stop()
+}
+
+func (t *timer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	active := t.stop()
+	t.when = t.clock.now.Add(d)
+	if d <= 0 {
+		t.clock.fire(t)
+		return active
+	}
+	t.clock.timers[t] = struct{}{}
+	t.clock.cond.Broadcast()
+	return active
+}
+
+// stop deactivates the timer and drains its channel, so no stale value is received after Stop or Reset.
+// Must be called with the lock held.
+func (t *timer) stop() bool {
+	_, active := t.clock.timers[t]
+	delete(t.clock.timers, t)
+	select {
+	case <-t.c:
+	default:
+	}
+	if active {
+		t.clock.cond.Broadcast()
+	}
+	return active
+}
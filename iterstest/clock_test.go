@@ -0,0 +1,63 @@
+package iterstest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClock(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewClock(start)
+
+	timer := clock.NewTimer(time.Second)
+	if clock.Timers() != 1 {
+		t.Fatalf("expected 1 timer, actual: %d", clock.Timers())
+	}
+
+	clock.Advance(time.Millisecond * 999)
+	select {
+	case <-timer.C():
+		t.Fatal("timer fired too early")
+	default:
+	}
+
+	clock.Advance(time.Millisecond)
+	select {
+	case now := <-timer.C():
+		if !now.Equal(start.Add(time.Second)) {
+			t.Errorf("unexpected time: %v", now)
+		}
+	default:
+		t.Fatal("timer did not fire")
+	}
+
+	if timer.Reset(time.Second) {
+		t.Error("expired timer reported as active")
+	}
+	if !timer.Stop() {
+		t.Error("active timer reported as inactive")
+	}
+	clock.Advance(time.Second)
+	select {
+	case <-timer.C():
+		t.Fatal("stopped timer fired")
+	default:
+	}
+}
+
+func TestClock_BlockUntil(t *testing.T) {
+	t.Parallel()
+
+	clock := NewClock(time.Time{})
+	done := make(chan struct{})
+	go func() {
+		<-clock.NewTimer(time.Minute).C()
+		close(done)
+	}()
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	<-done
+}
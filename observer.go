@@ -0,0 +1,120 @@
+package iters
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// RetryEvent describes an event of a retry sequence.
+type RetryEvent struct {
+	// Attempt is the number of the attempt. For OnGiveUp it is the number of attempts made.
+	Attempt int
+	// Delay is the delay before the attempt.
+	Delay time.Duration
+	// Elapsed is the time elapsed since the sequence started.
+	Elapsed time.Duration
+	// Err is the error of the previous attempt. It is known only by Do and DoValue.
+	Err error
+	// Reason is the reason of stopping. It is set only for OnGiveUp.
+	Reason StopReason
+}
+
+// Observer observes retries, for example, to log them or to collect metrics.
+type Observer interface {
+	// OnAttempt is called before an attempt.
+	OnAttempt(e RetryEvent)
+	// OnWait is called before waiting for the delay before an attempt.
+	OnWait(e RetryEvent)
+	// OnGiveUp is called when retries stop for any reason except the success of Do and DoValue
+	// or the consumer stopping the iteration.
+	OnGiveUp(e RetryEvent)
+}
+
+// ObserverFuncs is an Observer calling the functions. Nil functions are skipped.
+type ObserverFuncs struct {
+	Attempt func(e RetryEvent)
+	Wait    func(e RetryEvent)
+	GiveUp  func(e RetryEvent)
+}
+
+var _ Observer = ObserverFuncs{}
+
+// OnAttempt implements Observer.
+func (o ObserverFuncs) OnAttempt(e RetryEvent) {
+	if o.Attempt != nil {
+		o.Attempt(e)
+	}
+}
+
+// OnWait implements Observer.
+func (o ObserverFuncs) OnWait(e RetryEvent) {
+	if o.Wait != nil {
+		o.Wait(e)
+	}
+}
+
+// OnGiveUp implements Observer.
+func (o ObserverFuncs) OnGiveUp(e RetryEvent) {
+	if o.GiveUp != nil {
+		o.GiveUp(e)
+	}
+}
+
+// SlogObserver is an Observer writing structured logs. Attempts are logged at the debug level,
+// waits at the info level and giving up at the warn level.
+type SlogObserver struct {
+	logger *slog.Logger
+}
+
+var _ Observer = (*SlogObserver)(nil)
+
+// NewSlogObserver creates a new SlogObserver. If logger is nil, the default logger is used.
+func NewSlogObserver(logger *slog.Logger) *SlogObserver {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &SlogObserver{logger: logger}
+}
+
+// OnAttempt implements Observer.
+func (o *SlogObserver) OnAttempt(e RetryEvent) {
+	o.log(slog.LevelDebug, "retry attempt", e)
+}
+
+// OnWait implements Observer.
+func (o *SlogObserver) OnWait(e RetryEvent) {
+	o.log(slog.LevelInfo, "retry wait", e)
+}
+
+// OnGiveUp implements Observer.
+func (o *SlogObserver) OnGiveUp(e RetryEvent) {
+	attrs := []slog.Attr{
+		slog.Int("attempts", e.Attempt),
+		slog.Duration("elapsed", e.Elapsed),
+		slog.String("reason", e.Reason.String()),
+	}
+	if e.Err != nil {
+		attrs = append(attrs, slog.Any("error", e.Err))
+	}
+	o.logger.LogAttrs(context.Background(), slog.LevelWarn, "retry give up", attrs...)
+}
+
+func (o *SlogObserver) log(level slog.Level, msg string, e RetryEvent) {
+	attrs := []slog.Attr{
+		slog.Int("attempt", e.Attempt),
+		slog.Duration("delay", e.Delay),
+		slog.Duration("elapsed", e.Elapsed),
+	}
+	if e.Err != nil {
+		attrs = append(attrs, slog.Any("error", e.Err))
+	}
+	o.logger.LogAttrs(context.Background(), level, msg, attrs...)
+}
+
+// WithObserver sets the observer of retries.
+func WithObserver(o Observer) RetryOption {
+	return func(cfg *retryConfig) {
+		cfg.observer = o
+	}
+}
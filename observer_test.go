@@ -0,0 +1,78 @@
+package iters_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gotidy/iters"
+)
+
+func ExampleNewSlogObserver() {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey || a.Key == "elapsed" {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	_ = iters.Do(context.Background(), iters.Of(time.Millisecond), func(context.Context) error {
+		return io.EOF
+	}, iters.WithObserver(iters.NewSlogObserver(logger)))
+
+	// Output:
+	// level=DEBUG msg="retry attempt" attempt=0 delay=0s
+	// level=INFO msg="retry wait" attempt=1 delay=1ms error=EOF
+	// level=DEBUG msg="retry attempt" attempt=1 delay=1ms error=EOF
+	// level=WARN msg="retry give up" attempts=2 reason=exhausted error=EOF
+}
+
+func TestObserver(t *testing.T) {
+	t.Parallel()
+
+	var events []string
+	record := func(name string) func(iters.RetryEvent) {
+		return func(e iters.RetryEvent) {
+			events = append(events, name)
+		}
+	}
+	observer := iters.WithObserver(iters.ObserverFuncs{
+		Attempt: record("attempt"),
+		Wait:    record("wait"),
+		GiveUp:  record("give up"),
+	})
+
+	for attempt := range iters.Retry(context.Background(), iters.Repeat(time.Duration(0)), observer) {
+		if attempt == 1 {
+			break
+		}
+	}
+	assertEvents(t, []string{"attempt", "wait", "attempt"}, events)
+
+	events = nil
+	_ = iters.Do(context.Background(), iters.Repeat(time.Duration(0)), func(context.Context) error {
+		return nil
+	}, observer)
+	assertEvents(t, []string{"attempt"}, events)
+
+	events = nil
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	iters.Count2(iters.RetryAfterDelay(ctx, iters.Repeat(time.Duration(0)), observer))
+	assertEvents(t, []string{"wait", "give up"}, events)
+}
+
+func assertEvents(t *testing.T, expected, actual []string) {
+	t.Helper()
+
+	if !slices.Equal(expected, actual) {
+		t.Fatalf("expected events %v, actual: %v", expected, actual)
+	}
+}
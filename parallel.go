@@ -0,0 +1,184 @@
+package iters
+
+import (
+	"context"
+	"iter"
+	"sync"
+)
+
+// ParallelMap converts the sequence of values using a mapping function running on up to workers goroutines.
+// The order of the output values matches the order of the input values.
+// When the consumer stops the iteration or the context is done, the mapping function receives
+// the cancelled context and the iteration returns after the workers return. The sequence is stopped at
+// its next value without waiting for it, so an idle sequence does not block the consumer, as in FanIn.
+// If the sequence panics, the panic is propagated to the consumer.
+func ParallelMap[V1, V2 any](ctx context.Context, seq iter.Seq[V1], workers int, f func(ctx context.Context, v V1) V2) iter.Seq[V2] {
+	return parallelMap(ctx, seq, workers, f, true)
+}
+
+// ParallelMapUnordered converts the sequence of values using a mapping function running on up to workers goroutines.
+// The output values are yielded as soon as they are mapped.
+// When the consumer stops the iteration or the context is done, the mapping function receives
+// the cancelled context and the iteration returns after the workers return. The sequence is stopped at
+// its next value without waiting for it, so an idle sequence does not block the consumer, as in FanIn.
+// If the sequence panics, the panic is propagated to the consumer.
+func ParallelMapUnordered[V1, V2 any](
+	ctx context.Context, seq iter.Seq[V1], workers int, f func(ctx context.Context, v V1) V2,
+) iter.Seq[V2] {
+	return parallelMap(ctx, seq, workers, f, false)
+}
+
+func parallelMap[V1, V2 any](
+	ctx context.Context, seq iter.Seq[V1], workers int, f func(ctx context.Context, v V1) V2, ordered bool,
+) iter.Seq[V2] {
+	workers = max(1, workers)
+	return func(yield func(V2) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		var wg sync.WaitGroup
+		defer func() {
+			cancel()
+			wg.Wait()
+		}()
+		done := make(chan struct{})
+		defer close(done)
+
+		type item[V any] struct {
+			i int
+			v V
+		}
+		tasks := make(chan item[V1])
+		results := make(chan item[V2])
+		exits := make(chan producerExit)
+		// Slots limit the number of values in flight, including values waiting in the reorder buffer.
+		slots := make(chan struct{}, workers*2)
+
+		i := 0
+		produce(seq, func(v V1) bool {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return false
+			}
+			select {
+			case tasks <- item[V1]{i: i, v: v}:
+			case <-ctx.Done():
+				return false
+			}
+			i++
+			return true
+		}, func() { close(tasks) }, exits, done)
+
+		wg.Add(workers)
+		for range workers {
+			go func() {
+				defer wg.Done()
+
+				for {
+					var t item[V1]
+					var ok bool
+					select {
+					case t, ok = <-tasks:
+						if !ok {
+							return
+						}
+					case <-ctx.Done():
+						return
+					}
+					select {
+					case results <- item[V2]{i: t.i, v: f(ctx, t.v)}:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}()
+		}
+		go func() {
+			wg.Wait()
+			close(results)
+		}()
+
+		pending := make(map[int]V2)
+		next := 0
+		for {
+			var r item[V2]
+			var ok bool
+			select {
+			case r, ok = <-results:
+			case exit := <-exits:
+				exits = nil
+				if exit.panicked {
+					panic(exit.value)
+				}
+				continue
+			}
+			if !ok {
+				break
+			}
+			if !ordered {
+				<-slots
+				if !yield(r.v) {
+					return
+				}
+				continue
+			}
+			pending[r.i] = r.v
+			for v, ok := pending[next]; ok; v, ok = pending[next] {
+				delete(pending, next)
+				next++
+				<-slots
+				if !yield(v) {
+					return
+				}
+			}
+		}
+		// The workers return when the tasks channel is closed or the context is done.
+		// The producer closes the tasks channel before it reports the exit, so wait for it only in the first case.
+		if exits != nil && ctx.Err() == nil {
+			if exit := <-exits; exit.panicked {
+				panic(exit.value)
+			}
+		}
+	}
+}
+
+// ForEachConcurrent calls the function for each value of the sequence on up to limit goroutines.
+// It stops pulling values and cancels the context of the calls in flight on the first error and returns it
+// after all calls return. If the context is done, the context error is returned.
+func ForEachConcurrent[V any](ctx context.Context, seq iter.Seq[V], limit int, f func(ctx context.Context, v V) error) error {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	sem := make(chan struct{}, max(1, limit))
+	for v := range seq {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := f(ctx, v); err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel(err)
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
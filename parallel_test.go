@@ -0,0 +1,199 @@
+package iters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func ExampleParallelMap() {
+	seq := ParallelMap(context.Background(), slices.Values([]int{5, 4, 3, 2, 1}), 3, func(_ context.Context, v int) int {
+		time.Sleep(time.Millisecond * time.Duration(v))
+		return v * 10
+	})
+	fmt.Println(slices.Collect(seq))
+
+	// Output:
+	// [50 40 30 20 10]
+}
+
+func TestParallelMap(t *testing.T) {
+	t.Parallel()
+
+	input := slices.Collect(Trim(Linear(0, 1000, 1), 100))
+	for name, parallelMap := range map[string]func(context.Context, []int, int, func(context.Context, int) int) []int{
+		"ordered": func(ctx context.Context, vv []int, workers int, f func(context.Context, int) int) []int {
+			return slices.Collect(ParallelMap(ctx, slices.Values(vv), workers, f))
+		},
+		"unordered": func(ctx context.Context, vv []int, workers int, f func(context.Context, int) int) []int {
+			return slices.Sorted(ParallelMapUnordered(ctx, slices.Values(vv), workers, f))
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var active, maxActive atomic.Int32
+			actual := parallelMap(context.Background(), input, 4, func(_ context.Context, v int) int {
+				n := active.Add(1)
+				defer active.Add(-1)
+				storeMax(&maxActive, n)
+				time.Sleep(time.Microsecond * time.Duration(v%7*100))
+				return v * 2
+			})
+			expected := slices.Collect(Map(slices.Values(input), func(v int) int { return v * 2 }))
+			if !slices.Equal(expected, actual) {
+				t.Errorf("expected: %v, actual: %v", expected, actual)
+			}
+			if m := maxActive.Load(); m > 4 || m < 2 {
+				t.Errorf("unexpected number of concurrent calls: %d", m)
+			}
+		})
+	}
+}
+
+func TestParallelMap_break(t *testing.T) {
+	t.Parallel()
+
+	var pulled, active atomic.Int32
+	stopped := make(chan struct{})
+	source := func(yield func(int) bool) {
+		defer close(stopped)
+		for i := 0; ; i++ {
+			pulled.Add(1)
+			if !yield(i) {
+				return
+			}
+		}
+	}
+	for v := range ParallelMapUnordered(context.Background(), source, 4, func(ctx context.Context, v int) int {
+		active.Add(1)
+		defer active.Add(-1)
+		if v > 0 {
+			<-ctx.Done()
+		}
+		return v
+	}) {
+		assertEquals(t, 0, v)
+		break
+	}
+	assertEquals(t, int32(0), active.Load())
+	<-stopped
+	if pulled.Load() > 4*2+1 {
+		t.Errorf("too many values pulled: %d", pulled.Load())
+	}
+}
+
+func TestParallelMap_idle(t *testing.T) {
+	t.Parallel()
+
+	ch := make(chan int)
+	stopped := make(chan struct{})
+	source := func(yield func(int) bool) {
+		defer close(stopped)
+		if !yield(1) {
+			return
+		}
+		for v := range FromChan(ch) {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+	// The iteration returns while the source is blocked, it returns when the sequence ends.
+	for v := range ParallelMap(context.Background(), source, 2, func(_ context.Context, v int) int { return v }) {
+		assertEquals(t, 1, v)
+		break
+	}
+	close(ch)
+	<-stopped
+}
+
+func TestParallelMap_panic(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		assertEquals(t, "source failed", recover())
+	}()
+	source := func(yield func(int) bool) {
+		yield(1)
+		panic("source failed")
+	}
+	Count(ParallelMap(context.Background(), source, 2, func(_ context.Context, v int) int { return v }))
+	t.Error("panic is not propagated")
+}
+
+func ExampleForEachConcurrent() {
+	var sum atomic.Int64
+	err := ForEachConcurrent(context.Background(), slices.Values([]int64{1, 2, 3, 4, 5}), 2, func(_ context.Context, v int64) error {
+		sum.Add(v)
+		return nil
+	})
+	fmt.Println(sum.Load(), err)
+
+	// Output:
+	// 15 <nil>
+}
+
+func TestForEachConcurrent(t *testing.T) {
+	t.Parallel()
+
+	t.Run("first error", func(t *testing.T) {
+		t.Parallel()
+
+		errFailed := errors.New("failed")
+		var pulled, active, maxActive atomic.Int32
+		source := func(yield func(int) bool) {
+			for i := 0; ; i++ {
+				pulled.Add(1)
+				if !yield(i) {
+					return
+				}
+			}
+		}
+		err := ForEachConcurrent(context.Background(), source, 3, func(ctx context.Context, v int) error {
+			n := active.Add(1)
+			defer active.Add(-1)
+			storeMax(&maxActive, n)
+			if v == 10 {
+				return errFailed
+			}
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Millisecond):
+			}
+			return nil
+		})
+		assertEquals(t, errFailed, err)
+		assertEquals(t, int32(0), active.Load())
+		if m := maxActive.Load(); m > 3 {
+			t.Errorf("too many concurrent calls: %d", m)
+		}
+		if p := pulled.Load(); p > 10+3+1 {
+			t.Errorf("too many values pulled after the error: %d", p)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		err := ForEachConcurrent(ctx, Repeat(1), 2, func(context.Context, int) error {
+			cancel()
+			return nil
+		})
+		assertEquals(t, context.Canceled, err)
+	})
+}
+
+// storeMax stores n if it is greater than the stored value.
+func storeMax(v *atomic.Int32, n int32) {
+	for m := v.Load(); n > m; m = v.Load() {
+		if v.CompareAndSwap(m, n) {
+			return
+		}
+	}
+}
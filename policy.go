@@ -0,0 +1,335 @@
+package iters
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Policy is a sequence of delays composed from generators and transformers.
+// A policy is described by a string of stages separated by "|", for example:
+//
+//	exp(10ms,5s,x2)|jitter(0.2)|take(6)|within(1m)
+//
+// The first stage is a generator:
+//
+//	exp(start,max[,xFactor])  Exponential, the factor defaults to x2
+//	linear(start,max,step)    Linear
+//	fib(start,max)            Fibonacci
+//	poly(start,max,degree)    Polynomial
+//	repeat(delay)             Repeat
+//	of(delay[,delay...])      Of
+//
+// The following stages are transformers:
+//
+//	jitter(factor)            Jitter
+//	fulljitter                FullJitter
+//	equaljitter               EqualJitter
+//	decorrelated(base)        DecorrelatedJitter
+//	take(count)               Trim
+//	within(max)               MaxElapsedTime, also stops before a delay that would end after max
+//
+// Durations must be positive and max must not be less than start. The exp factor must be greater than 1,
+// the poly degree must be positive, the jitter factor must be within [0, 1] and the take count must not be negative.
+//
+// Policy implements encoding.TextMarshaler and encoding.TextUnmarshaler, so it can be stored in configurations.
+// The zero Policy has no delays.
+type Policy struct {
+	stages []policyStage
+}
+
+type policyStage struct {
+	name string
+	// args are the canonical arguments.
+	args []string
+	seq  func(delays iter.Seq[time.Duration]) iter.Seq[time.Duration]
+}
+
+func (s policyStage) String() string {
+	if len(s.args) == 0 {
+		return s.name
+	}
+	return s.name + "(" + strings.Join(s.args, ",") + ")"
+}
+
+// ParsePolicy parses the policy description.
+func ParsePolicy(s string) (Policy, error) {
+	var p Policy
+	if strings.TrimSpace(s) == "" {
+		return p, nil
+	}
+	for i, desc := range strings.Split(s, "|") {
+		stage, generator, err := parsePolicyStage(strings.TrimSpace(desc))
+		if err != nil {
+			return Policy{}, fmt.Errorf("iters: invalid policy stage %q: %w", desc, err)
+		}
+		if generator != (i == 0) {
+			if generator {
+				return Policy{}, fmt.Errorf("iters: invalid policy stage %q: generator must be the first stage", desc)
+			}
+			return Policy{}, fmt.Errorf("iters: invalid policy stage %q: the first stage must be a generator", desc)
+		}
+		p.stages = append(p.stages, stage)
+	}
+	return p, nil
+}
+
+// Delays returns the sequence of delays of the policy.
+func (p Policy) Delays() iter.Seq[time.Duration] {
+	var seq iter.Seq[time.Duration]
+	for _, stage := range p.stages {
+		seq = stage.seq(seq)
+	}
+	return seq
+}
+
+// String returns the canonical description of the policy.
+func (p Policy) String() string {
+	stages := make([]string, len(p.stages))
+	for i, stage := range p.stages {
+		stages[i] = stage.String()
+	}
+	return strings.Join(stages, "|")
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (p Policy) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (p *Policy) UnmarshalText(text []byte) error {
+	parsed, err := ParsePolicy(string(text))
+	if err != nil {
+		return err
+	}
+	*p = parsed
+	return nil
+}
+
+var errPolicyArgs = errors.New("wrong number of arguments")
+
+// parsePolicyStage parses a stage and reports whether it is a generator.
+func parsePolicyStage(desc string) (policyStage, bool, error) {
+	name, args, err := splitPolicyStage(desc)
+	if err != nil {
+		return policyStage{}, false, err
+	}
+
+	var p policyArgs
+	stage := policyStage{name: name}
+	generator := true
+	switch name {
+	case "exp":
+		p.count(args, 2, 3)
+		start, max, factor := p.duration(args, 0), p.duration(args, 1), 2.0
+		if len(args) == 3 {
+			factor = p.factor(args[2])
+		}
+		p.bounds(start, max)
+		p.check(factor > 1, "factor %s is not greater than 1", formatFloat(factor))
+		stage.args = []string{start.String(), max.String(), "x" + formatFloat(factor)}
+		stage.seq = func(iter.Seq[time.Duration]) iter.Seq[time.Duration] { return Exponential(start, max, factor) }
+	case "linear":
+		p.count(args, 3, 3)
+		start, max, step := p.duration(args, 0), p.duration(args, 1), p.duration(args, 2)
+		p.bounds(start, max)
+		stage.args = []string{start.String(), max.String(), step.String()}
+		stage.seq = func(iter.Seq[time.Duration]) iter.Seq[time.Duration] { return Linear(start, max, step) }
+	case "fib":
+		p.count(args, 2, 2)
+		start, max := p.duration(args, 0), p.duration(args, 1)
+		p.bounds(start, max)
+		stage.args = []string{start.String(), max.String()}
+		stage.seq = func(iter.Seq[time.Duration]) iter.Seq[time.Duration] { return Fibonacci(start, max) }
+	case "poly":
+		p.count(args, 3, 3)
+		start, max, degree := p.duration(args, 0), p.duration(args, 1), p.float(args, 2)
+		p.bounds(start, max)
+		p.check(degree > 0, "degree %s is not positive", formatFloat(degree))
+		stage.args = []string{start.String(), max.String(), formatFloat(degree)}
+		stage.seq = func(iter.Seq[time.Duration]) iter.Seq[time.Duration] { return Polynomial(start, max, degree) }
+	case "repeat":
+		p.count(args, 1, 1)
+		delay := p.duration(args, 0)
+		stage.args = []string{delay.String()}
+		stage.seq = func(iter.Seq[time.Duration]) iter.Seq[time.Duration] { return Repeat(delay) }
+	case "of":
+		p.count(args, 1, -1)
+		delays := make([]time.Duration, len(args))
+		for i := range args {
+			delays[i] = p.duration(args, i)
+			stage.args = append(stage.args, delays[i].String())
+		}
+		stage.seq = func(iter.Seq[time.Duration]) iter.Seq[time.Duration] { return Of(delays...) }
+	default:
+		generator = false
+		stage.seq, stage.args = p.transformer(name, args)
+	}
+	if p.err != nil {
+		return policyStage{}, false, p.err
+	}
+	return stage, generator, nil
+}
+
+func (p *policyArgs) transformer(
+	name string, args []string,
+) (func(iter.Seq[time.Duration]) iter.Seq[time.Duration], []string) {
+	switch name {
+	case "jitter":
+		p.count(args, 1, 1)
+		factor := p.float(args, 0)
+		p.check(factor >= 0 && factor <= 1, "jitter factor %s is out of [0, 1]", formatFloat(factor))
+		return func(seq iter.Seq[time.Duration]) iter.Seq[time.Duration] {
+			return Jitter(seq, factor)
+		}, []string{formatFloat(factor)}
+	case "fulljitter":
+		p.count(args, 0, 0)
+		return FullJitter[time.Duration], nil
+	case "equaljitter":
+		p.count(args, 0, 0)
+		return EqualJitter[time.Duration], nil
+	case "decorrelated":
+		p.count(args, 1, 1)
+		base := p.duration(args, 0)
+		return func(seq iter.Seq[time.Duration]) iter.Seq[time.Duration] {
+			return DecorrelatedJitter(seq, base)
+		}, []string{base.String()}
+	case "take":
+		p.count(args, 1, 1)
+		count := p.int(args, 0)
+		p.check(count >= 0, "count %d is negative", count)
+		return func(seq iter.Seq[time.Duration]) iter.Seq[time.Duration] {
+			return Trim(seq, count)
+		}, []string{strconv.Itoa(count)}
+	case "within":
+		p.count(args, 1, 1)
+		max := p.duration(args, 0)
+		return func(seq iter.Seq[time.Duration]) iter.Seq[time.Duration] {
+			return within(seq, max)
+		}, []string{max.String()}
+	default:
+		p.fail(fmt.Errorf("unknown stage %q", name))
+		return nil, nil
+	}
+}
+
+// within stops the delays when the next delay would end after the specified time has elapsed since the first delay
+// was taken, as WithMaxElapsedTime does. Delays hinted after the policy are bounded only by WithMaxElapsedTime.
+func within(seq iter.Seq[time.Duration], max time.Duration) iter.Seq[time.Duration] {
+	return func(yield func(time.Duration) bool) {
+		var start time.Time
+		for d := range seq {
+			if start.IsZero() {
+				start = time.Now()
+			}
+			if time.Since(start)+d > max {
+				return
+			}
+			if !yield(d) {
+				return
+			}
+		}
+	}
+}
+
+// splitPolicyStage splits a stage description into the name and the arguments.
+func splitPolicyStage(desc string) (string, []string, error) {
+	name, rest, found := strings.Cut(desc, "(")
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", nil, errors.New("empty stage")
+	}
+	if !found {
+		return name, nil, nil
+	}
+	rest, ok := strings.CutSuffix(strings.TrimSpace(rest), ")")
+	if !ok {
+		return "", nil, errors.New("missing closing parenthesis")
+	}
+	if strings.TrimSpace(rest) == "" {
+		return name, nil, nil
+	}
+	args := strings.Split(rest, ",")
+	for i := range args {
+		args[i] = strings.TrimSpace(args[i])
+	}
+	return name, args, nil
+}
+
+// policyArgs parses arguments of a stage and keeps the first error.
+type policyArgs struct {
+	err error
+}
+
+func (p *policyArgs) fail(err error) {
+	if p.err == nil {
+		p.err = err
+	}
+}
+
+// count checks the number of arguments. Negative max means no limit.
+func (p *policyArgs) count(args []string, min, max int) {
+	if len(args) < min || (max >= 0 && len(args) > max) {
+		p.fail(errPolicyArgs)
+	}
+}
+
+func (p *policyArgs) duration(args []string, i int) time.Duration {
+	if i >= len(args) {
+		return 0
+	}
+	d, err := time.ParseDuration(args[i])
+	if err != nil {
+		p.fail(err)
+		return 0
+	}
+	p.check(d > 0, "duration %s is not positive", d)
+	return d
+}
+
+// bounds checks that the maximal delay is not less than the start one.
+func (p *policyArgs) bounds(start, max time.Duration) {
+	p.check(max >= start, "max %s is less than start %s", max, start)
+}
+
+// check fails with the formatted error if the condition is false.
+func (p *policyArgs) check(ok bool, format string, args ...any) {
+	if !ok {
+		p.fail(fmt.Errorf(format, args...))
+	}
+}
+
+func (p *policyArgs) float(args []string, i int) float64 {
+	if i >= len(args) {
+		return 0
+	}
+	f, err := strconv.ParseFloat(args[i], 64)
+	if err != nil {
+		p.fail(err)
+	}
+	return f
+}
+
+func (p *policyArgs) int(args []string, i int) int {
+	if i >= len(args) {
+		return 0
+	}
+	n, err := strconv.Atoi(args[i])
+	if err != nil {
+		p.fail(err)
+	}
+	return n
+}
+
+// factor parses a factor with the optional "x" prefix.
+func (p *policyArgs) factor(arg string) float64 {
+	return p.float([]string{strings.TrimPrefix(arg, "x")}, 0)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
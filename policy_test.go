@@ -0,0 +1,122 @@
+package iters
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+func ExampleParsePolicy() {
+	policy, err := ParsePolicy("exp(10ms, 1s) | take(8)")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(policy)
+	fmt.Println(slices.Collect(policy.Delays()))
+
+	// Output:
+	// exp(10ms,1s,x2)|take(8)
+	// [10ms 20ms 40ms 80ms 160ms 320ms 640ms 1s]
+}
+
+func ExamplePolicy_UnmarshalText() {
+	var config struct {
+		Retry Policy `json:"retry"`
+	}
+	if err := json.Unmarshal([]byte(`{"retry": "fib(1s,1m)|jitter(0.2)|take(6)|within(1m)"}`), &config); err != nil {
+		panic(err)
+	}
+	fmt.Println(config.Retry)
+
+	// Output:
+	// fib(1s,1m0s)|jitter(0.2)|take(6)|within(1m0s)
+}
+
+func TestParsePolicy(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		policy   string
+		expected []time.Duration
+	}{
+		{"repeat(1s)|take(2)", []time.Duration{time.Second, time.Second}},
+		{"of(1s, 2s, 3s)", []time.Duration{time.Second, 2 * time.Second, 3 * time.Second}},
+		{"linear(1s,3s,1s)|take(4)", []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second}},
+		{"exp(1s,10s,x3)|take(4)", []time.Duration{time.Second, 3 * time.Second, 9 * time.Second, 10 * time.Second}},
+		{"poly(1s,10s,2)|take(3)", []time.Duration{time.Second, 4 * time.Second, 9 * time.Second}},
+		{"repeat(1s)|jitter(0)|within(1h)|take(1)", []time.Duration{time.Second}},
+		{"of(10s,1m)|within(30s)", []time.Duration{10 * time.Second}},
+	} {
+		t.Run(tc.policy, func(t *testing.T) {
+			policy, err := ParsePolicy(tc.policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertEquals(t, fmt.Sprint(tc.expected), fmt.Sprint(slices.Collect(policy.Delays())))
+
+			reparsed, err := ParsePolicy(policy.String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertEquals(t, policy.String(), reparsed.String())
+		})
+	}
+
+	for _, policy := range []string{
+		"take(5)",
+		"repeat(1s)|exp(1s,2s)",
+		"repeat(1s, 2s)",
+		"repeat(1x)",
+		"repeat(1s",
+		"unknown(1s)",
+		"repeat(1s)||take(1)",
+		"repeat(1s)|take(x)",
+		"repeat(1s)|fulljitter(1)",
+		"exp(10ms,5s,x0)",
+		"exp(10ms,5s,x1)",
+		"exp(-1s,5s)",
+		"exp(5s,1s)",
+		"linear(1s,3s,0s)",
+		"fib(2s,1s)",
+		"poly(1s,10s,0)",
+		"repeat(-1s)",
+		"repeat(0s)",
+		"of(1s,-1s)",
+		"repeat(1s)|jitter(3)",
+		"repeat(1s)|jitter(-0.5)",
+		"repeat(1s)|decorrelated(0s)",
+		"repeat(1s)|take(-1)",
+		"repeat(1s)|within(-1m)",
+	} {
+		t.Run(policy, func(t *testing.T) {
+			if _, err := ParsePolicy(policy); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		policy, err := ParsePolicy(" ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if policy.Delays() != nil {
+			t.Error("empty policy has delays")
+		}
+		assertEquals(t, "", policy.String())
+	})
+
+	t.Run("randomized", func(t *testing.T) {
+		policy, err := ParsePolicy("repeat(1s)|fulljitter|equaljitter|decorrelated(1ms)|take(100)")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for d := range policy.Delays() {
+			if d < 0 || d > time.Second {
+				t.Fatalf("delay %s out of range", d)
+			}
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package iters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"time"
+)
+
+// ErrPollTimeout is returned by PollUntil when the condition is not met before the delays are exhausted,
+// the maximum elapsed time is exceeded or the context deadline is exceeded.
+var ErrPollTimeout = errors.New("iters: poll timeout")
+
+// PollUntil calls the condition until it reports done and returns the value of the last call.
+// The first call occurs immediately, the following calls happen after the delays, as in Retry.
+// Polling stops on the first error returned by the condition and returns it as is.
+// If the context is cancelled, the context error is returned. On timeout the returned error wraps ErrPollTimeout
+// and the error of the stop reason (see RetryOutcome). Progress can be reported with WithObserver.
+func PollUntil[T any](
+	ctx context.Context,
+	delays iter.Seq[time.Duration],
+	cond func(ctx context.Context) (v T, done bool, err error),
+	opts ...RetryOption,
+) (T, error) {
+	cfg := newRetryConfig(opts)
+	l := retryLoop{ctx: ctx, cfg: &cfg}
+	var v T
+	for range l.seq(delays, true) {
+		var done bool
+		var err error
+		v, done, err = pollAttempt(ctx, cfg.attemptTimeout, cond)
+		if err != nil {
+			l.stop(StopNonRetryable, nil)
+			return v, err
+		}
+		if done {
+			l.stop(StopSucceeded, nil)
+			return v, nil
+		}
+	}
+
+	switch err := l.outcome.Err; {
+	case errors.Is(err, context.Canceled):
+		return v, err
+	case l.outcome.Reason == StopCircuitOpen || l.outcome.Reason == StopBudgetExhausted:
+		return v, err
+	case err != nil:
+		return v, fmt.Errorf("%w: %w", ErrPollTimeout, err)
+	default:
+		return v, ErrPollTimeout
+	}
+}
+
+// pollAttempt calls the condition with the attempt context.
+func pollAttempt[T any](
+	ctx context.Context, timeout time.Duration, cond func(ctx context.Context) (T, bool, error),
+) (T, bool, error) {
+	ctx, cancel := attemptContext(ctx, timeout)
+	defer cancel()
+
+	return cond(ctx)
+}
@@ -0,0 +1,73 @@
+package iters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+)
+
+func ExamplePollUntil() {
+	status := 0
+	v, err := PollUntil(context.Background(), Repeat(time.Millisecond), func(context.Context) (int, bool, error) {
+		status += 25
+		return status, status == 100, nil
+	})
+	fmt.Println(v, err)
+
+	// Output:
+	// 100 <nil>
+}
+
+func TestPollUntil(t *testing.T) {
+	t.Parallel()
+
+	notDone := func(context.Context) (int, bool, error) { return 1, false, nil }
+
+	t.Run("timeout", func(t *testing.T) {
+		v, err := PollUntil(context.Background(), Trim(Repeat(time.Duration(0)), 3), notDone)
+		assertEquals(t, 1, v)
+		assertEquals(t, ErrPollTimeout, err)
+
+		_, err = PollUntil(context.Background(), Repeat(time.Millisecond), notDone, WithMaxElapsedTime(time.Millisecond*5))
+		if !errors.Is(err, ErrPollTimeout) || !errors.Is(err, ErrMaxElapsedTime) {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*5)
+		defer cancel()
+		_, err = PollUntil(ctx, Repeat(time.Millisecond), notDone)
+		if !errors.Is(err, ErrPollTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		_, err := PollUntil(ctx, Repeat(time.Millisecond), func(context.Context) (int, bool, error) {
+			cancel()
+			return 0, false, nil
+		})
+		assertEquals(t, context.Canceled, err)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		calls := 0
+		_, err := PollUntil(context.Background(), Repeat(time.Duration(0)), func(context.Context) (int, bool, error) {
+			calls++
+			return 0, false, io.EOF
+		})
+		assertEquals(t, io.EOF, err)
+		assertEquals(t, 1, calls)
+	})
+
+	t.Run("progress", func(t *testing.T) {
+		attempts := 0
+		_, _ = PollUntil(context.Background(), Trim(Repeat(time.Duration(0)), 4), notDone,
+			WithObserver(ObserverFuncs{Attempt: func(RetryEvent) { attempts++ }}))
+		assertEquals(t, 5, attempts)
+	})
+}
@@ -0,0 +1,22 @@
+package iters
+
+import "math/rand/v2"
+
+// globalRand is the random generator using the global random source. It is safe for concurrent use.
+var globalRand = rand.New(globalSource{})
+
+// globalSource is the rand.Source that reads from the global random source.
+type globalSource struct{}
+
+func (globalSource) Uint64() uint64 {
+	return rand.Uint64() //nolint:gosec
+}
+
+// randOrGlobal returns the random generator or the generator using the global source if r is nil.
+// A random generator passed by a caller is used as is, so it must not be shared between concurrent iterations.
+func randOrGlobal(r *rand.Rand) *rand.Rand {
+	if r == nil {
+		return globalRand
+	}
+	return r
+}
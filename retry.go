@@ -2,43 +2,446 @@ package iters
 
 import (
 	"context"
+	"errors"
 	"iter"
+	"strconv"
 	"time"
 )
 
+var (
+	// ErrDelayExceedsDeadline is the reason of stopping retries when the next delay exceeds the context deadline.
+	ErrDelayExceedsDeadline = errors.New("iters: retry delay exceeds context deadline")
+	// ErrMaxElapsedTime is the reason of stopping retries when the maximum elapsed time is exceeded.
+	ErrMaxElapsedTime = errors.New("iters: retry max elapsed time exceeded")
+)
+
+// StopReason is the reason why a retry sequence ended.
+type StopReason int
+
+const (
+	// StopNone means the sequence has not ended yet.
+	StopNone StopReason = iota
+	// StopExhausted means the delays sequence was exhausted.
+	StopExhausted
+	// StopBreak means the consumer stopped the iteration.
+	StopBreak
+	// StopSucceeded means the operation of Do or DoValue succeeded.
+	StopSucceeded
+	// StopNonRetryable means the operation of Do or DoValue returned a non-retryable error.
+	StopNonRetryable
+	// StopCanceled means the context was done.
+	StopCanceled
+	// StopDeadline means the next delay exceeded the context deadline.
+	StopDeadline
+	// StopMaxElapsed means the maximum elapsed time was exceeded.
+	StopMaxElapsed
+	// StopCircuitOpen means the circuit breaker did not allow an attempt.
+	StopCircuitOpen
+	// StopBudgetExhausted means the retry budget was exhausted.
+	StopBudgetExhausted
+)
+
+var stopReasons = [...]string{
+	StopNone:            "none",
+	StopExhausted:       "exhausted",
+	StopBreak:           "break",
+	StopSucceeded:       "succeeded",
+	StopNonRetryable:    "non-retryable",
+	StopCanceled:        "canceled",
+	StopDeadline:        "deadline",
+	StopMaxElapsed:      "max elapsed time",
+	StopCircuitOpen:     "circuit open",
+	StopBudgetExhausted: "budget exhausted",
+}
+
+// String returns the name of the reason.
+func (r StopReason) String() string {
+	if r < 0 || int(r) >= len(stopReasons) {
+		return "StopReason(" + strconv.Itoa(int(r)) + ")"
+	}
+	return stopReasons[r]
+}
+
+// RetryOutcome describes how a retry sequence ended.
+type RetryOutcome struct {
+	// Reason is the reason why the sequence ended.
+	Reason StopReason
+	// Err is the error corresponding to the reason: the context error, ErrDelayExceedsDeadline, ErrMaxElapsedTime,
+	// ErrCircuitOpen or ErrRetryBudgetExhausted. Nil for other reasons.
+	Err error
+	// Attempts is the number of attempts made.
+	Attempts int
+	// Waited is the total time spent waiting for delays.
+	Waited time.Duration
+}
+
 // Retry returns sequence that allows to retry with specified delays.
 // The first iteration occurs immediately (retry, delay, retry, delay, retry).
-func Retry[R int, D time.Duration](ctx context.Context, delays iter.Seq[time.Duration]) iter.Seq2[int, time.Duration] {
+func Retry[R int, D time.Duration](ctx context.Context, delays iter.Seq[time.Duration], opts ...RetryOption) iter.Seq2[int, time.Duration] {
+	cfg := newRetryConfig(opts)
 	return func(yield func(int, time.Duration) bool) {
-		if !yield(0, 0) {
-			return
-		}
-		for attempt, delay := range RetryAfterDelay(ctx, delays) {
-			if !yield(attempt, delay) {
+		l := retryLoop{ctx: ctx, cfg: &cfg}
+		l.run(delays, true, yield)
+	}
+}
+
+// RetryAfterDelay returns sequence that allows to retry with specified delays.
+// Started from delay (delay, retry, delay, retry).
+func RetryAfterDelay[R int, D time.Duration](ctx context.Context, delays iter.Seq[time.Duration], opts ...RetryOption) iter.Seq2[int, time.Duration] {
+	cfg := newRetryConfig(opts)
+	return func(yield func(int, time.Duration) bool) {
+		l := retryLoop{ctx: ctx, cfg: &cfg}
+		l.run(delays, false, yield)
+	}
+}
+
+// Attempt describes an attempt of a retry sequence.
+type Attempt struct {
+	// Number is the attempt number, starting with 0.
+	Number int
+	// Delay is the delay waited before the attempt.
+	Delay time.Duration
+}
+
+// RetryWithTimeout returns sequence that allows to retry with specified delays, as Retry does,
+// and yields a context for each attempt. The attempt context is derived from ctx with the timeout and
+// is cancelled when the next iteration starts or the sequence ends. If timeout is not positive,
+// the attempt context has no timeout.
+func RetryWithTimeout(
+	ctx context.Context, delays iter.Seq[time.Duration], timeout time.Duration, opts ...RetryOption,
+) iter.Seq2[context.Context, Attempt] {
+	return func(yield func(context.Context, Attempt) bool) {
+		for attempt, delay := range Retry(ctx, delays, opts...) {
+			if !yieldAttempt(ctx, timeout, Attempt{Number: attempt, Delay: delay}, yield) {
 				return
 			}
 		}
 	}
 }
 
-// RetryAfterDelay returns sequence that allows to retry with specified delays.
-// Started from delay (delay, retry, delay, retry).
-func RetryAfterDelay[R int, D time.Duration](ctx context.Context, delays iter.Seq[time.Duration]) iter.Seq2[int, time.Duration] {
+func yieldAttempt(
+	ctx context.Context, timeout time.Duration, attempt Attempt, yield func(context.Context, Attempt) bool,
+) bool {
+	ctx, cancel := attemptContext(ctx, timeout)
+	defer cancel()
+
+	return yield(ctx, attempt)
+}
+
+// attemptContext returns a context of a single attempt.
+func attemptContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// retryLoop is a single run of a retry sequence.
+type retryLoop struct {
+	ctx context.Context
+	cfg *retryConfig
+	// outcome is the outcome of the run.
+	outcome RetryOutcome
+	// start is the time the run started.
+	start time.Time
+	// lastErr is the error of the last attempt, set by the consumer if it is known.
+	lastErr error
+	// clamped reports that the delay has been clamped to the context deadline.
+	clamped bool
+}
+
+// seq returns the sequence of attempts of the loop.
+func (l *retryLoop) seq(delays iter.Seq[time.Duration], immediate bool) iter.Seq2[int, time.Duration] {
 	return func(yield func(int, time.Duration) bool) {
-		if delays == nil {
+		l.run(delays, immediate, yield)
+	}
+}
+
+// run yields attempts. If immediate is true, the first attempt is yielded without a delay.
+func (l *retryLoop) run(delays iter.Seq[time.Duration], immediate bool, yield func(int, time.Duration) bool) {
+	defer l.report()
+
+	l.outcome = RetryOutcome{}
+	l.start = l.cfg.clock.Now()
+	l.lastErr = nil
+	if immediate {
+		if !l.allow() {
 			return
 		}
-		attempts := 1
-		for delay := range delays {
-			select {
-			case <-ctx.Done():
-				return
-			case <-time.After(delay):
-			}
-			if !yield(attempts, delay) {
-				return
-			}
-			attempts++
+		l.outcome.Attempts++
+		l.observe(Observer.OnAttempt, 0, 0)
+		if !yield(0, 0) {
+			l.breakOff()
+			return
+		}
+	}
+	if delays == nil {
+		l.stop(StopExhausted, nil)
+		return
+	}
+
+	w := waiter{clock: l.cfg.clock}
+	defer w.stop()
+
+	start := l.cfg.clock.Now()
+	attempts := 1
+	for delay := range delays {
+		delay, ok := l.fitDeadline(delay)
+		if !ok {
+			l.stop(StopDeadline, ErrDelayExceedsDeadline)
+			return
+		}
+		if l.cfg.maxElapsed > 0 && l.cfg.clock.Now().Sub(start)+delay > l.cfg.maxElapsed {
+			l.stop(StopMaxElapsed, ErrMaxElapsedTime)
+			return
+		}
+		// The breaker and the budget are checked before the wait, so retries that are not allowed do not wait in vain.
+		if l.cfg.breaker != nil && l.cfg.breaker.State() == BreakerOpen {
+			l.stop(StopCircuitOpen, ErrCircuitOpen)
+			return
+		}
+		if l.cfg.budget != nil && !l.cfg.budget.Withdraw() {
+			l.stop(StopBudgetExhausted, ErrRetryBudgetExhausted)
+			return
+		}
+		l.observe(Observer.OnWait, attempts, delay)
+		waitStart := l.cfg.clock.Now()
+		ok = w.wait(l.ctx, delay)
+		l.outcome.Waited += l.cfg.clock.Now().Sub(waitStart)
+		if !ok {
+			l.refund()
+			l.stop(StopCanceled, l.ctx.Err())
+			return
+		}
+		// The breaker may have opened during the wait, so the attempt is admitted after it.
+		if !l.allow() {
+			l.refund()
+			return
+		}
+		l.outcome.Attempts++
+		l.observe(Observer.OnAttempt, attempts, delay)
+		if !yield(attempts, delay) {
+			l.breakOff()
+			return
+		}
+		attempts++
+	}
+	l.stop(StopExhausted, nil)
+}
+
+// allow reports whether the next attempt is allowed by the circuit breaker.
+func (l *retryLoop) allow() bool {
+	if l.cfg.breaker != nil && !l.cfg.breaker.Allow() {
+		l.stop(StopCircuitOpen, ErrCircuitOpen)
+		return false
+	}
+	return true
+}
+
+// release releases the attempt allowed by the circuit breaker without recording its result.
+func (l *retryLoop) release() {
+	if l.cfg.breaker != nil {
+		l.cfg.breaker.Release()
+	}
+}
+
+// refund returns the retry withdrawn from the budget for an attempt that was not made.
+func (l *retryLoop) refund() {
+	if l.cfg.budget != nil {
+		l.cfg.budget.Refund()
+	}
+}
+
+// stop records the reason of stopping.
+func (l *retryLoop) stop(reason StopReason, err error) {
+	l.outcome.Reason = reason
+	l.outcome.Err = err
+}
+
+// breakOff records that the consumer stopped the iteration, unless the consumer has recorded its own reason.
+func (l *retryLoop) breakOff() {
+	if l.outcome.Reason == StopNone {
+		l.stop(StopBreak, nil)
+	}
+}
+
+// report copies the outcome to the destination set by WithOutcome and notifies the observer about giving up.
+func (l *retryLoop) report() {
+	if l.cfg.outcome != nil {
+		*l.cfg.outcome = l.outcome
+	}
+	if l.outcome.Reason != StopBreak && l.outcome.Reason != StopSucceeded {
+		l.observe(Observer.OnGiveUp, l.outcome.Attempts, 0)
+	}
+}
+
+// observe notifies the observer about the event.
+func (l *retryLoop) observe(notify func(Observer, RetryEvent), attempt int, delay time.Duration) {
+	if l.cfg.observer == nil {
+		return
+	}
+	notify(l.cfg.observer, RetryEvent{
+		Attempt: attempt,
+		Delay:   delay,
+		Elapsed: l.cfg.clock.Now().Sub(l.start),
+		Err:     l.lastErr,
+		Reason:  l.outcome.Reason,
+	})
+}
+
+// fitDeadline adjusts the delay to the context deadline according to the deadline policy.
+// Returns false if retries should stop.
+func (l *retryLoop) fitDeadline(delay time.Duration) (time.Duration, bool) {
+	if l.cfg.deadline == deadlineIgnore {
+		return delay, true
+	}
+	deadline, ok := l.ctx.Deadline()
+	if !ok {
+		return delay, true
+	}
+	// The context deadline is set by the system clock, so the remaining time is measured by it too.
+	remaining := time.Until(deadline)
+	switch l.cfg.deadline {
+	case deadlineStop:
+		return delay, delay < remaining
+	case deadlineClamp:
+		limit := remaining - l.cfg.deadlineMargin
+		if delay <= limit {
+			return delay, true
 		}
+		if l.clamped || limit < 0 {
+			return delay, false
+		}
+		l.clamped = true
+		return limit, true
+	}
+	return delay, true
+}
+
+// waiter waits for delays reusing a single timer.
+type waiter struct {
+	clock Clock
+	timer Timer
+}
+
+// wait waits for the delay. Returns false if the context is done before the delay has elapsed.
+func (w *waiter) wait(ctx context.Context, delay time.Duration) bool {
+	if delay <= 0 {
+		return ctx.Err() == nil
+	}
+	if w.timer == nil {
+		w.timer = w.clock.NewTimer(delay)
+	} else {
+		w.timer.Reset(delay)
+	}
+	select {
+	case <-ctx.Done():
+		w.timer.Stop()
+		return false
+	case <-w.timer.C():
+		return true
+	}
+}
+
+// stop releases the timer.
+func (w *waiter) stop() {
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+}
+
+type deadlinePolicy int
+
+const (
+	deadlineIgnore deadlinePolicy = iota
+	deadlineStop
+	deadlineClamp
+)
+
+// RetryOption configures retries.
+type RetryOption func(*retryConfig)
+
+type retryConfig struct {
+	classifier     Classifier
+	clock          Clock
+	deadline       deadlinePolicy
+	deadlineMargin time.Duration
+	maxElapsed     time.Duration
+	outcome        *RetryOutcome
+	attemptTimeout time.Duration
+	breaker        *CircuitBreaker
+	budget         *RetryBudget
+	observer       Observer
+}
+
+func newRetryConfig(opts []RetryOption) retryConfig {
+	cfg := retryConfig{
+		classifier: DefaultClassifier,
+		clock:      systemClock{},
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// WithClassifier sets the classifier of attempt errors used by Do and DoValue.
+// Permanent errors and the cancelled context are always terminal, regardless of the classifier.
+func WithClassifier(c Classifier) RetryOption {
+	return func(cfg *retryConfig) {
+		if c != nil {
+			cfg.classifier = c
+		}
+	}
+}
+
+// WithClock sets the clock used to wait for delays. By default, the system clock is used.
+func WithClock(c Clock) RetryOption {
+	return func(cfg *retryConfig) {
+		cfg.clock = clockOrSystem(c)
+	}
+}
+
+// WithDeadlineStop stops retries immediately if the next delay would exceed the context deadline,
+// instead of waiting until the context is done. Do and DoValue report it with ErrDelayExceedsDeadline.
+// The remaining time is measured by the system clock, as the context deadline is, regardless of WithClock.
+func WithDeadlineStop() RetryOption {
+	return func(cfg *retryConfig) {
+		cfg.deadline = deadlineStop
+	}
+}
+
+// WithDeadlineClamp shortens the delay that would exceed the context deadline,
+// so the last attempt happens the margin before the deadline. Retries stop after the last attempt.
+// The remaining time is measured by the system clock, as the context deadline is, regardless of WithClock.
+func WithDeadlineClamp(margin time.Duration) RetryOption {
+	return func(cfg *retryConfig) {
+		cfg.deadline = deadlineClamp
+		cfg.deadlineMargin = margin
+	}
+}
+
+// WithMaxElapsedTime stops retries when the next delay would end after the specified time has elapsed
+// since the first delay started, so delays hinted by a server cannot exceed it either.
+// It is reported with StopMaxElapsed and ErrMaxElapsedTime.
+func WithMaxElapsedTime(max time.Duration) RetryOption {
+	return func(cfg *retryConfig) {
+		cfg.maxElapsed = max
+	}
+}
+
+// WithOutcome sets the destination for the outcome of a retry sequence.
+// The outcome is written when the sequence ends. A sequence with the outcome must not be iterated concurrently.
+func WithOutcome(outcome *RetryOutcome) RetryOption {
+	return func(cfg *retryConfig) {
+		cfg.outcome = outcome
+	}
+}
+
+// WithAttemptTimeout sets the timeout of each attempt of Do and DoValue.
+// The operation receives a context derived from the parent context with the timeout.
+func WithAttemptTimeout(timeout time.Duration) RetryOption {
+	return func(cfg *retryConfig) {
+		cfg.attemptTimeout = timeout
 	}
 }
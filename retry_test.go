@@ -2,7 +2,9 @@ package iters
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"testing"
 	"time"
 )
 
@@ -45,20 +47,166 @@ func ExampleRetry_break() {
 	// 5 16ms
 }
 
-func ExampleRetry_ctx() {
-	ctx, cancel := context.WithCancel(context.Background())
-	done := make(chan struct{})
-	go func() {
-		for range Retry(ctx, Repeat(time.Millisecond*100)) {
-			fmt.Print()
+func BenchmarkRetryAfterDelay(b *testing.B) {
+	ctx := context.Background()
+	b.Run("timer", func(b *testing.B) {
+		b.ReportAllocs()
+		for range RetryAfterDelay(ctx, Trim(Repeat(time.Nanosecond), b.N)) {
 		}
-		fmt.Println("stopped")
-		close(done)
-	}()
+	})
+	b.Run("no delay", func(b *testing.B) {
+		b.ReportAllocs()
+		for range RetryAfterDelay(ctx, Trim(Repeat(time.Duration(0)), b.N)) {
+		}
+	})
+}
+
+func TestRetry_deadline(t *testing.T) {
+	t.Parallel()
+
+	t.Run("stop", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*200)
+		defer cancel()
+		start := time.Now()
+		err := Do(ctx, Repeat(time.Second), func(context.Context) error {
+			return errors.New("failed")
+		}, WithDeadlineStop())
+		if !errors.Is(err, ErrDelayExceedsDeadline) {
+			t.Errorf("expected ErrDelayExceedsDeadline, actual: %v", err)
+		}
+		if time.Since(start) > time.Millisecond*100 {
+			t.Error("retry waited for the deadline")
+		}
+	})
+
+	t.Run("clamp", func(t *testing.T) {
+		t.Parallel()
+
+		const margin = time.Millisecond * 50
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*200)
+		defer cancel()
+		deadline, _ := ctx.Deadline()
+		var delays []time.Duration
+		// The delay is clamped between the attempts, so it is bounded by the remaining times at the attempts.
+		var first, second time.Time
+		for attempt, delay := range Retry(ctx, Repeat(time.Second), WithDeadlineClamp(margin)) {
+			if attempt == 1 {
+				second = time.Now()
+			}
+			delays = append(delays, delay)
+			if attempt == 0 {
+				first = time.Now()
+			}
+		}
+		assertEquals(t, 2, len(delays))
+		if delays[1] > deadline.Sub(first)-margin || delays[1] < deadline.Sub(second)-margin {
+			t.Errorf("unexpected clamped delay: %s", delays[1])
+		}
+		if ctx.Err() != nil {
+			t.Error("the last attempt happened after the deadline")
+		}
+	})
+
+	t.Run("no deadline", func(t *testing.T) {
+		t.Parallel()
+
+		assertEquals(t, 4, Count2(Retry(context.Background(), Trim(Repeat(time.Millisecond), 3), WithDeadlineStop())))
+	})
+}
+
+func ExampleWithOutcome() {
+	var outcome RetryOutcome
+	for range Retry(context.Background(), Trim(Repeat(time.Millisecond), 3), WithOutcome(&outcome)) {
+	}
+	fmt.Println(outcome.Reason, outcome.Attempts, outcome.Err)
+
+	for attempt := range Retry(context.Background(), Repeat(time.Millisecond), WithOutcome(&outcome)) {
+		if attempt == 1 {
+			break
+		}
+	}
+	fmt.Println(outcome.Reason, outcome.Attempts, outcome.Err)
+
+	// Output:
+	// exhausted 4 <nil>
+	// break 2 <nil>
+}
+
+func TestRetryOutcome(t *testing.T) {
+	t.Parallel()
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		var outcome RetryOutcome
+		for range Retry(ctx, Repeat(time.Hour), WithOutcome(&outcome)) {
+			cancel()
+		}
+		assertEquals(t, StopCanceled, outcome.Reason)
+		assertEquals(t, context.Canceled, outcome.Err)
+		assertEquals(t, 1, outcome.Attempts)
+	})
+
+	t.Run("do", func(t *testing.T) {
+		var outcome RetryOutcome
+		_ = Do(context.Background(), Repeat(time.Duration(0)), func(context.Context) error {
+			return nil
+		}, WithOutcome(&outcome))
+		assertEquals(t, StopSucceeded, outcome.Reason)
+
+		err := Do(context.Background(), Repeat(time.Duration(0)), func(context.Context) error {
+			return Permanent(errors.New("failed"))
+		}, WithOutcome(&outcome))
+		assertEquals(t, StopNonRetryable, outcome.Reason)
+		assertEquals(t, true, IsPermanent(err))
+	})
+
+	t.Run("string", func(t *testing.T) {
+		assertEquals(t, "deadline", StopDeadline.String())
+		assertEquals(t, "StopReason(100)", StopReason(100).String())
+	})
+}
 
-	cancel()
-	<-done
+func ExampleRetryWithTimeout() {
+	for ctx, attempt := range RetryWithTimeout(context.Background(), Trim(Repeat(time.Millisecond), 2), time.Millisecond*10) {
+		<-ctx.Done()
+		fmt.Println(attempt.Number, attempt.Delay, ctx.Err())
+	}
 
 	// Output:
-	// stopped
+	// 0 0s context deadline exceeded
+	// 1 1ms context deadline exceeded
+	// 2 1ms context deadline exceeded
+}
+
+func TestRetryWithTimeout(t *testing.T) {
+	t.Parallel()
+
+	var contexts []context.Context
+	for ctx := range RetryWithTimeout(context.Background(), Trim(Repeat(time.Duration(0)), 2), 0) {
+		for _, prev := range contexts {
+			if prev.Err() == nil {
+				t.Error("the previous attempt context is not cancelled")
+			}
+		}
+		if ctx.Err() != nil {
+			t.Error("the attempt context is cancelled")
+		}
+		contexts = append(contexts, ctx)
+	}
+	assertEquals(t, 3, len(contexts))
+	assertEquals(t, context.Canceled, contexts[2].Err())
+
+	calls := 0
+	err := Do(context.Background(), Trim(Repeat(time.Duration(0)), 1), func(ctx context.Context) error {
+		calls++
+		<-ctx.Done()
+		return ctx.Err()
+	}, WithAttemptTimeout(time.Millisecond))
+	assertEquals(t, 2, calls)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, actual: %v", err)
+	}
 }
@@ -0,0 +1,183 @@
+// Package retryhttp provides an http.RoundTripper that retries requests using iters retry sequences.
+package retryhttp
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"iter"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gotidy/iters"
+)
+
+const (
+	// drainLimit is the maximal number of bytes read from a body of a response that is retried,
+	// so the connection can be reused.
+	drainLimit = 4096
+	// defaultMaxRetryAfter is the default maximal delay requested by the Retry-After header that is waited.
+	defaultMaxRetryAfter = time.Minute
+)
+
+// Transport is an http.RoundTripper that retries idempotent requests with the specified delays.
+// Request bodies are rewound with Request.GetBody, requests with a body that cannot be rewound are not retried.
+// The delay requested by the Retry-After header of 429 and 503 responses takes precedence over the delays.
+// If the requested delay exceeds MaxRetryAfter, the response is returned without retrying.
+// If all attempts fail, the result of the last attempt is returned. If no attempt is made, for example, because
+// the circuit breaker is open, the error of iters.DoValue is returned, such as iters.ErrCircuitOpen.
+type Transport struct {
+	// Base is the underlying RoundTripper. Defaults to http.DefaultTransport.
+	Base http.RoundTripper
+	// Delays is the sequence of delays between attempts, as used by iters.Retry. If nil, requests are not retried.
+	Delays iter.Seq[time.Duration]
+	// Retryable reports whether the result of an attempt should be retried. Defaults to Retryable.
+	Retryable func(resp *http.Response, err error) bool
+	// Options are the options of retries, for example, iters.WithMaxElapsedTime or iters.WithCircuitBreaker.
+	// Attempts are made by iters.DoValue, so results are recorded in the circuit breaker and the retry budget
+	// as it does, and the classifier receives network errors and errors of retryable responses.
+	// iters.WithAttemptTimeout is not applied, as the response body is read after the attempt.
+	// The Transport is used concurrently, so Options must be safe for concurrent use: iters.WithOutcome is not.
+	Options []iters.RetryOption
+	// MaxRetryAfter is the maximal delay requested by the Retry-After header that is waited. Defaults to 1 minute.
+	MaxRetryAfter time.Duration
+	// Clock is the clock used to wait for delays and to resolve Retry-After dates. Defaults to the system clock.
+	// It takes precedence over iters.WithClock in Options.
+	Clock iters.Clock
+}
+
+var _ http.RoundTripper = (*Transport)(nil)
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if t.Delays == nil || !IsIdempotent(req) || !rewindable(req) {
+		return base.RoundTrip(req)
+	}
+	retryable := t.Retryable
+	if retryable == nil {
+		retryable = Retryable
+	}
+	maxRetryAfter := t.MaxRetryAfter
+	if maxRetryAfter <= 0 {
+		maxRetryAfter = defaultMaxRetryAfter
+	}
+	opts, now := t.Options, time.Now
+	if t.Clock != nil {
+		opts, now = append(slices.Clip(opts), iters.WithClock(t.Clock)), t.Clock.Now
+	}
+
+	var (
+		resp     *http.Response
+		err      error
+		attempts int
+		hints    iters.DelayHints
+	)
+	_, doErr := iters.DoValue(req.Context(), hints.Seq(t.Delays), func(context.Context) (*http.Response, error) {
+		r := req
+		if attempts > 0 {
+			discard(resp)
+			resp = nil
+			if r, err = rewind(req); err != nil {
+				return nil, iters.Permanent(err)
+			}
+		}
+		attempts++
+		resp, err = base.RoundTrip(r)
+		if !retryable(resp, err) {
+			return resp, iters.Permanent(err)
+		}
+		attemptErr := err
+		if attemptErr == nil {
+			attemptErr = &statusError{status: resp.Status}
+		}
+		if d, ok := retryAfter(resp, now()); ok {
+			if d > maxRetryAfter {
+				return nil, iters.Permanent(attemptErr)
+			}
+			hints.Hint(d)
+		}
+		return nil, attemptErr
+	}, opts...)
+	if attempts == 0 {
+		return nil, doErr
+	}
+	return resp, err
+}
+
+// statusError is an error of an attempt that received a retryable response.
+type statusError struct {
+	status string
+}
+
+// Error implements the error interface.
+func (e *statusError) Error() string {
+	return "retryhttp: retryable response: " + e.status
+}
+
+// IsIdempotent reports whether the request is idempotent and can be retried: its method is idempotent or
+// it has the Idempotency-Key or X-Idempotency-Key header.
+func IsIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != "" || req.Header.Get("X-Idempotency-Key") != ""
+}
+
+// Retryable reports whether the result of an attempt should be retried. It retries network errors,
+// except context and TLS certificate verification errors, and 408, 429, 500, 502, 503 and 504 responses.
+func Retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		var certErr *tls.CertificateVerificationError
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) &&
+			!errors.As(err, &certErr)
+	}
+	switch resp.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusInternalServerError,
+		http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// rewindable reports whether the request body can be sent again.
+func rewindable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// rewind returns a copy of the request with a new body.
+func rewind(req *http.Request) (*http.Request, error) {
+	r := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return r, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	r.Body = body
+	return r, nil
+}
+
+// retryAfter returns the delay requested by the Retry-After header of 429 and 503 responses.
+func retryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp == nil || (resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable) {
+		return 0, false
+	}
+	return iters.ParseRetryAfter(resp.Header.Get("Retry-After"), now)
+}
+
+// discard drains and closes the body of the response.
+func discard(resp *http.Response) {
+	if resp == nil {
+		return
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, drainLimit))
+	_ = resp.Body.Close()
+}
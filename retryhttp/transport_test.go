@@ -0,0 +1,247 @@
+package retryhttp
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gotidy/iters"
+	"github.com/gotidy/iters/iterstest"
+)
+
+// failingServer responds with the status until the number of failures is reached, then responds with the body
+// of the request.
+func failingServer(t *testing.T, failures int32, status int, header http.Header) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= failures {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(status)
+			return
+		}
+		_, _ = io.Copy(w, r.Body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func TestTransport(t *testing.T) {
+	t.Parallel()
+
+	t.Run("body", func(t *testing.T) {
+		t.Parallel()
+
+		srv, calls := failingServer(t, 2, http.StatusServiceUnavailable, nil)
+		client := &http.Client{Transport: &Transport{Delays: iters.Repeat(time.Millisecond)}}
+		resp, err := client.Post(srv.URL, "text/plain", strings.NewReader("hello"))
+		if err == nil {
+			defer resp.Body.Close()
+		}
+		// POST is not idempotent.
+		if err != nil || resp.StatusCode != http.StatusServiceUnavailable || calls.Load() != 1 {
+			t.Fatalf("unexpected result: %v, %v, %d", resp, err, calls.Load())
+		}
+
+		req, _ := http.NewRequest(http.MethodPut, srv.URL, strings.NewReader("hello"))
+		resp, err = client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK || string(body) != "hello" || calls.Load() != 3 {
+			t.Fatalf("unexpected result: %d, %q, %d", resp.StatusCode, body, calls.Load())
+		}
+	})
+
+	t.Run("give up", func(t *testing.T) {
+		t.Parallel()
+
+		srv, calls := failingServer(t, 10, http.StatusBadGateway, nil)
+		client := &http.Client{Transport: &Transport{Delays: iters.Trim(iters.Repeat(time.Millisecond), 2)}}
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusBadGateway || calls.Load() != 3 {
+			t.Fatalf("unexpected result: %d, %d", resp.StatusCode, calls.Load())
+		}
+	})
+
+	t.Run("not retryable", func(t *testing.T) {
+		t.Parallel()
+
+		srv, calls := failingServer(t, 10, http.StatusNotFound, nil)
+		client := &http.Client{Transport: &Transport{Delays: iters.Repeat(time.Millisecond)}}
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound || calls.Load() != 1 {
+			t.Fatalf("unexpected result: %d, %d", resp.StatusCode, calls.Load())
+		}
+	})
+
+	t.Run("retry after", func(t *testing.T) {
+		t.Parallel()
+
+		srv, calls := failingServer(t, 1, http.StatusTooManyRequests, http.Header{"Retry-After": {"0"}})
+		client := &http.Client{Transport: &Transport{Delays: iters.Repeat(time.Hour)}}
+		start := time.Now()
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || calls.Load() != 2 || time.Since(start) > time.Minute {
+			t.Fatalf("unexpected result: %d, %d", resp.StatusCode, calls.Load())
+		}
+	})
+
+	t.Run("retry after limit", func(t *testing.T) {
+		t.Parallel()
+
+		srv, calls := failingServer(t, 1, http.StatusServiceUnavailable, http.Header{"Retry-After": {"86400"}})
+		client := &http.Client{Transport: &Transport{Delays: iters.Repeat(time.Millisecond), MaxRetryAfter: time.Hour}}
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusServiceUnavailable || calls.Load() != 1 {
+			t.Fatalf("unexpected result: %d, %d", resp.StatusCode, calls.Load())
+		}
+	})
+
+	t.Run("retry after date", func(t *testing.T) {
+		t.Parallel()
+
+		now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+		clock := iterstest.NewClock(now)
+		srv, calls := failingServer(t, 1, http.StatusTooManyRequests,
+			http.Header{"Retry-After": {now.Add(time.Second * 2).Format(http.TimeFormat)}})
+		client := &http.Client{Transport: &Transport{Delays: iters.Repeat(time.Hour), Clock: clock}}
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			resp, err := client.Get(srv.URL)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			_ = resp.Body.Close()
+		}()
+
+		// The date is resolved by the clock, so the request waits for the clock.
+		select {
+		case <-done:
+			t.Fatal("the Retry-After date is not resolved by the clock")
+		case <-time.After(time.Millisecond * 10):
+		}
+		clock.BlockUntil(1)
+		clock.Advance(time.Second * 2)
+		<-done
+		if calls.Load() != 2 {
+			t.Fatalf("unexpected calls: %d", calls.Load())
+		}
+	})
+
+	t.Run("network error", func(t *testing.T) {
+		t.Parallel()
+
+		var calls atomic.Int32
+		errNetwork := errors.New("network")
+		transport := &Transport{
+			Base: roundTripperFunc(func(*http.Request) (*http.Response, error) {
+				calls.Add(1)
+				return nil, errNetwork
+			}),
+			Delays: iters.Trim(iters.Repeat(time.Duration(0)), 3),
+		}
+		req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+		resp, err := transport.RoundTrip(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+		if !errors.Is(err, errNetwork) || calls.Load() != 4 {
+			t.Fatalf("unexpected result: %v, %d", err, calls.Load())
+		}
+	})
+
+	t.Run("circuit breaker", func(t *testing.T) {
+		t.Parallel()
+
+		srv, calls := failingServer(t, 10, http.StatusServiceUnavailable, nil)
+		breaker := iters.NewCircuitBreaker(iters.CircuitBreakerConfig{ConsecutiveFailures: 2, CoolDown: time.Hour})
+		client := &http.Client{Transport: &Transport{
+			Delays:  iters.Repeat(time.Duration(0)),
+			Options: []iters.RetryOption{iters.WithCircuitBreaker(breaker)},
+		}}
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusServiceUnavailable || calls.Load() != 2 || breaker.State() != iters.BreakerOpen {
+			t.Fatalf("unexpected result: %d, %d, %s", resp.StatusCode, calls.Load(), breaker.State())
+		}
+
+		// No attempt is made while the breaker is open.
+		resp, err = client.Get(srv.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		if !errors.Is(err, iters.ErrCircuitOpen) || calls.Load() != 2 {
+			t.Fatalf("unexpected result: %v, %v, %d", resp, err, calls.Load())
+		}
+	})
+
+	t.Run("retry budget", func(t *testing.T) {
+		t.Parallel()
+
+		srv, _ := failingServer(t, 0, http.StatusOK, nil)
+		budget := iters.NewRetryBudget(iters.RetryBudgetConfig{Ratio: 1})
+		client := &http.Client{Transport: &Transport{
+			Delays:  iters.Repeat(time.Duration(0)),
+			Options: []iters.RetryOption{iters.WithRetryBudget(budget)},
+		}}
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if !budget.Withdraw() {
+			t.Error("successful request did not earn a retry")
+		}
+	})
+}
+
+func TestIsIdempotent(t *testing.T) {
+	t.Parallel()
+
+	req, _ := http.NewRequest(http.MethodPost, "http://example.com", nil)
+	if IsIdempotent(req) {
+		t.Error("POST is idempotent")
+	}
+	req.Header.Set("Idempotency-Key", "key")
+	if !IsIdempotent(req) {
+		t.Error("POST with Idempotency-Key is not idempotent")
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}